@@ -3,42 +3,78 @@ package blocksync
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math/big"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
 
 	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/airchains-network/decentralized-sequencer/store"
 	"github.com/airchains-network/decentralized-sequencer/types"
 	"github.com/airchains-network/decentralized-sequencer/utils"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
-func StoreEVMBlock(client *ethclient.Client, ctx context.Context, blockIndex int, ldb *leveldb.DB, ldt *leveldb.DB) {
-	zerolog.TimeFieldFormat = time.RFC3339
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
-	blockData, err := client.BlockByNumber(ctx, big.NewInt(int64(blockIndex)))
+// storeEVMBlock indexes block blockIndex and its transactions and returns the
+// next block to index. After a reorg that is the first block past the common
+// ancestor rather than blockIndex+1. errBlockNotReady is returned while the
+// block does not exist yet or is not confirmationDepth blocks deep.
+func storeEVMBlock(ctx context.Context, client *ethclient.Client, signer gethTypes.Signer, blockIndex int, ldb store.Namespace, ldt store.Namespace, confirmationDepth uint64) (int, error) {
+	head, err := client.BlockNumber(ctx)
 	if err != nil {
+		return blockIndex, fmt.Errorf("error fetching latest block number: %v", err)
+	}
+	if head < uint64(blockIndex)+confirmationDepth {
+		return blockIndex, errBlockNotReady
+	}
+
+	var raw json.RawMessage
+	if err = client.Client().CallContext(ctx, &raw, "eth_getBlockByNumber", hexutil.EncodeUint64(uint64(blockIndex)), true); err != nil {
+		return blockIndex, fmt.Errorf("failed to get block data for block number %d: %v", blockIndex, err)
+	}
+	blockData, hash, err := decodeEVMBlock(raw)
+	if errors.Is(err, errBlockNotReady) {
+		return blockIndex, err
+	}
+	if err != nil {
+		return blockIndex, fmt.Errorf("error decoding block %d: %v", blockIndex, err)
+	}
 
-		// errMessage := fmt.Sprintf("Failed to get block data for block number %d: %s", blockIndex, err)
-		// log.Warn().Str("module", "blocksync").Err(err).Msg(errMessage)
-		time.Sleep(3 * time.Second)
-		StoreEVMBlock(client, ctx, blockIndex, ldb, ldt)
+	resumeIndex, err := checkEVMParent(client, ctx, ldb, ldt, blockIndex, blockData.ParentHash().String())
+	if err != nil {
+		return blockIndex, err
+	}
+	if resumeIndex != blockIndex {
+		return resumeIndex, nil
 	}
 
+	receipts, err := fetchEVMReceipts(ctx, client.Client(), blockData.Transactions())
+	if err != nil {
+		return blockIndex, err
+	}
+	if err = writeEVMBlock(ldb, ldt, signer, blockData, hash, receipts); err != nil {
+		return blockIndex, err
+	}
+	return blockIndex + 1, nil
+}
+
+// writeEVMBlock stores blockData as block_N, under hash as reported by the
+// RPC, and its transactions as the next txns-N records. Block and
+// transactions are written in a single batch, so blockCount only moves
+// together with them.
+func writeEVMBlock(ldb store.Namespace, ldt store.Namespace, signer gethTypes.Signer, blockData *gethTypes.Block, hash string, receipts []*gethTypes.Receipt) error {
 	var block = types.BlockStruct{
 		BaseFeePerGas:    utils.ToString(blockData.Header().BaseFee),
 		Difficulty:       utils.ToString(blockData.Difficulty().String()),
 		ExtraData:        utils.ToString(blockData.Extra()),
 		GasLimit:         utils.ToString(blockData.GasLimit()),
 		GasUsed:          utils.ToString(blockData.GasUsed()),
-		Hash:             utils.ToString(blockData.Hash().String()),
+		Hash:             hash,
 		LogsBloom:        utils.ToString(blockData.Bloom()),
 		Miner:            utils.ToString(blockData.Coinbase().String()),
 		MixHash:          utils.ToString(blockData.MixDigest().String()),
@@ -57,40 +93,43 @@ func StoreEVMBlock(client *ethclient.Client, ctx context.Context, blockIndex int
 	}
 	data, err := json.Marshal(block)
 	if err != nil {
-		errMessage := fmt.Sprintf("Error marshalling block data: %s", err)
-		log.Error().Str("module", "blocksync").Err(err).Msg(errMessage)
-
-	}
-	key := fmt.Sprintf("block_%s", block.Number)
-	err = ldb.Put([]byte(key), data, nil)
-	if err != nil {
-		errMessage := fmt.Sprintf("Error inserting block data into database: %s", err)
-		log.Error().Str("module", "blocksync").Err(err).Msg(errMessage)
+		return fmt.Errorf("error marshalling block data: %v", err)
 	}
 
 	transactions := blockData.Transactions()
-	if transactions == nil {
-		log.Info().Str("module", "blocksync").Msg(fmt.Sprintf("No Txn In  %s", block.Number))
+	if len(receipts) != len(transactions) {
+		return fmt.Errorf("block %s has %d transactions but %d receipts", block.Number, len(transactions), len(receipts))
 	}
-
-	for i := 0; i < block.TransactionCount; i++ {
-		StoreEVMTransactions(client, ctx, ldt, transactions[i].Hash().String(), blockIndex, block.Hash)
+	batch := new(store.Batch)
+	if len(transactions) > 0 {
+		transactionNumber, err := ldt.Counter("txnCount")
+		if err != nil {
+			return err
+		}
+		for i, tx := range transactions {
+			txData, err := evmTransactionData(tx, signer, receipts[i], blockData.NumberU64(), block.Hash)
+			if err != nil {
+				return err
+			}
+			if err = insertTxnEVM(batch, ldt, txData, evmReceiptData(receipts[i]), transactionNumber); err != nil {
+				return fmt.Errorf("failed to insert transaction: %v", err)
+			}
+			transactionNumber++
+		}
 	}
-
-	blockCount := blockIndex + 1
-	err = ldb.Put([]byte("blockCount"), []byte(strconv.Itoa(blockCount)), nil)
-	if err != nil {
-		errMessage := fmt.Sprintf("Error inserting block count into database: %s", err)
-		log.Error().Str("module", "blocksync").Err(err).Msg(errMessage)
+	batch.Put(ldb, []byte(fmt.Sprintf("block_%s", block.Number)), data)
+	batch.Put(ldb, []byte("blockCount"), []byte(strconv.FormatUint(blockData.NumberU64()+1, 10)))
+	if err = ldb.Write(batch); err != nil {
+		return fmt.Errorf("error inserting block %s into database: %v", block.Number, err)
 	}
-	StoreEVMBlock(client, ctx, blockIndex+1, ldb, ldt)
+	return nil
 }
 
-func getLastProcessedBlock(db *leveldb.DB) int {
+func getLastProcessedBlock(db store.Namespace) int {
 	lastBlockKey := []byte("lastProcessedBlock")
-	data, err := db.Get(lastBlockKey, nil)
+	data, err := db.Get(lastBlockKey)
 	if err != nil {
-		if err == leveldb.ErrNotFound {
+		if err == store.ErrNotFound {
 			// If not found, return 0 indicating start from the beginning
 			return 0
 		}
@@ -100,7 +139,7 @@ func getLastProcessedBlock(db *leveldb.DB) int {
 	return lastBlockNum
 }
 
-func StoreWasmBlock(ldb *leveldb.DB, ldt *leveldb.DB, JsonRPC string, JsonAPI string) {
+func StoreWasmBlock(ldb store.Namespace, ldt store.Namespace, JsonRPC string, JsonAPI string) {
 	rpcUrl := fmt.Sprintf("%s/cosmos/base/tendermint/v1beta1/blocks/latest", JsonAPI)
 	res, resErr := http.Get(rpcUrl)
 	if resErr != nil {
@@ -131,7 +170,7 @@ func StoreWasmBlock(ldb *leveldb.DB, ldt *leveldb.DB, JsonRPC string, JsonAPI st
 
 }
 
-func OldWasmBlocks(JsonRPC string, JsonAPI string, startBlock int, numLatestBlock int, db *leveldb.DB, txnDB *leveldb.DB) {
+func OldWasmBlocks(JsonRPC string, JsonAPI string, startBlock int, numLatestBlock int, db store.Namespace, txnDB store.Namespace) {
 	for i := startBlock; i <= numLatestBlock; i++ {
 		rpcUrl := fmt.Sprintf("%s/block?height=%d", JsonRPC, i)
 		resp, err := http.Get(rpcUrl)
@@ -169,13 +208,7 @@ func OldWasmBlocks(JsonRPC string, JsonAPI string, startBlock int, numLatestBloc
 
 			blockKey := []byte("Block" + strconv.Itoa(i))
 
-			if err = db.Put(blockKey, resultJSON, nil); err != nil {
-				log.Error().Str("module", "blocksync").Err(err).Msg("")
-			}
-
-			blockCount := i + 1
-			err = db.Put([]byte("blockCount"), []byte(strconv.Itoa(blockCount)), nil)
-			if err != nil {
+			if err = putBlock(db, blockKey, resultJSON, i+1); err != nil {
 				log.Error().Str("module", "blocksync").Err(err).Msg("")
 			}
 		}
@@ -199,7 +232,7 @@ func GetWasmCurrentBlock(JsonAPI string) (BlockObject, error) {
 	return data, nil
 }
 
-func watchWasmBlocks(JsonRPC string, JsonAPI string, currentBlockHeight int, db *leveldb.DB, txnDB *leveldb.DB) {
+func watchWasmBlocks(JsonRPC string, JsonAPI string, currentBlockHeight int, db store.Namespace, txnDB store.Namespace) {
 	var currentBlock BlockObject
 	for {
 		latestBlock, err := GetWasmCurrentBlock(JsonAPI)
@@ -250,20 +283,14 @@ func watchWasmBlocks(JsonRPC string, JsonAPI string, currentBlockHeight int, db
 				log.Fatal().Str("body", string(body)).Msg(err.Error())
 			}
 
-			blockKey := []byte("Block" + latestBlock.Block.Header.Height)
-			if err = db.Put(blockKey, resultJSON, nil); err != nil {
-
-			}
-
 			height, err := strconv.Atoi(latestBlock.Block.Header.Height)
 			if err != nil {
 				log.Error().Str("module", "blocksync").Err(err).Msg("")
 				continue
 			}
-			blockCount := height + 1
-			err = db.Put([]byte("blockCount"), []byte(strconv.Itoa(blockCount)), nil)
-			if err != nil {
-
+			blockKey := []byte("Block" + latestBlock.Block.Header.Height)
+			if err = putBlock(db, blockKey, resultJSON, height+1); err != nil {
+				log.Error().Str("module", "blocksync").Err(err).Msg("")
 			}
 		} else {
 			fmt.Println("Result key not found in response")
@@ -273,13 +300,13 @@ func watchWasmBlocks(JsonRPC string, JsonAPI string, currentBlockHeight int, db
 	}
 }
 
-func NewWasmBlocks(JsonRPC string, JsonAPI string, currentBlock int, db *leveldb.DB, txnDB *leveldb.DB) {
+func NewWasmBlocks(JsonRPC string, JsonAPI string, currentBlock int, db store.Namespace, txnDB store.Namespace) {
 	watchWasmBlocks(JsonRPC, JsonAPI, currentBlock, db, txnDB)
 }
 
 // * SVM chain
 
-func StoreSVMBlock(ldb *leveldb.DB, ldt *leveldb.DB, JsonRPC, JsonAPI string) {
+func StoreSVMBlock(ldb store.Namespace, ldt store.Namespace, JsonRPC, JsonAPI string) {
 	initSVMRPC(JsonRPC)
 
 	latestIndex, latestIndexErr := SVMLatestBlockCheck()
@@ -295,14 +322,14 @@ func StoreSVMBlock(ldb *leveldb.DB, ldt *leveldb.DB, JsonRPC, JsonAPI string) {
 	NewSVMBlock(latestIndex, ldb, ldt)
 }
 
-func OldSVMBlock(startIndex, latestIndex int, ldb, ldt *leveldb.DB) {
+func OldSVMBlock(startIndex, latestIndex int, ldb, ldt store.Namespace) {
 	for i := startIndex; i <= latestIndex; i++ {
 		SVMBlockStore(i, ldb, ldt)
 		fmt.Println("old block store : ", i)
 	}
 }
 
-func NewSVMBlock(currentIndex int, ldb, ldt *leveldb.DB) {
+func NewSVMBlock(currentIndex int, ldb, ldt store.Namespace) {
 	for {
 		latestIndex, latestIndexErr := SVMLatestBlockCheck()
 		if latestIndexErr != nil {
@@ -325,7 +352,7 @@ func NewSVMBlock(currentIndex int, ldb, ldt *leveldb.DB) {
 
 }
 
-func SVMBlockStore(blockNumber int, ldb, ldt *leveldb.DB) {
+func SVMBlockStore(blockNumber int, ldb, ldt store.Namespace) {
 	res, resErr := SVMBlockCall(blockNumber)
 	if resErr != nil {
 		log.Error().Str("module", "blocksync").Err(resErr).Msg("")
@@ -336,18 +363,21 @@ func SVMBlockStore(blockNumber int, ldb, ldt *leveldb.DB) {
 		log.Error().Str("module", "blocksync").Err(err).Msg("")
 	}
 
-	blockKey := []byte("Block" + strconv.Itoa(blockNumber))
-	if err = ldb.Put(blockKey, resJson, nil); err != nil {
-		log.Error().Str("module", "blocksync").Err(err).Msg("")
-	}
-
 	for i := 0; i < len(res.Result.Transactions); i++ {
 		StoreSVMTransaction(ldt, res.Result.Transactions[i])
 	}
 
-	blockCount := blockNumber + 1
-	err = ldb.Put([]byte("blockCount"), []byte(strconv.Itoa(blockCount)), nil)
-	if err != nil {
+	blockKey := []byte("Block" + strconv.Itoa(blockNumber))
+	if err = putBlock(ldb, blockKey, resJson, blockNumber+1); err != nil {
 		log.Error().Str("module", "blocksync").Err(err).Msg("")
 	}
 }
+
+// putBlock stores a block and the new blockCount in one batch, so blockCount
+// never points past a block that was not written.
+func putBlock(db store.Namespace, blockKey []byte, data []byte, blockCount int) error {
+	batch := new(store.Batch)
+	batch.Put(db, blockKey, data)
+	batch.Put(db, []byte("blockCount"), []byte(strconv.Itoa(blockCount)))
+	return db.Write(batch)
+}
@@ -4,23 +4,21 @@ import (
 	"context"
 	"fmt"
 	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/airchains-network/decentralized-sequencer/store"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/spf13/viper"
-	"github.com/syndtr/goleveldb/leveldb"
-	"os"
-	"path/filepath"
 	"sync"
 )
 
-func StartIndexer(wg *sync.WaitGroup, client *ethclient.Client, ctx context.Context, blockDatabaseConnection *leveldb.DB, txnDatabaseConnection *leveldb.DB, latestBlock int) {
+func StartIndexer(wg *sync.WaitGroup, client *ethclient.Client, ctx context.Context, blockDatabaseConnection store.Namespace, txnDatabaseConnection store.Namespace, latestBlock int) {
 	wg.Done()
-	bsgConfig, err := LoadConfig()
+	bsgConfig, err := config.Current()
 	if err != nil {
 		fmt.Println(err)
+		return
 	}
 
 	if bsgConfig.Station.StationType == "EVM" || bsgConfig.Station.StationType == "evm" {
-		StoreEVMBlock(client, ctx, latestBlock, blockDatabaseConnection, txnDatabaseConnection)
+		RunEVMIndexer(ctx, client, latestBlock, blockDatabaseConnection, txnDatabaseConnection, bsgConfig.Station)
 	} else if bsgConfig.Station.StationType == "WASM" || bsgConfig.Station.StationType == "wasm" {
 		JsonRPC := bsgConfig.Station.StationRPC
 		JsonAPI := bsgConfig.Station.StationAPI
@@ -32,27 +30,3 @@ func StartIndexer(wg *sync.WaitGroup, client *ethclient.Client, ctx context.Cont
 	}
 
 }
-
-func LoadConfig() (config config.Config, err error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return config, err // Return error, perhaps log it as well
-	}
-	configDir := filepath.Join(homeDir, ".tracks/config")
-
-	_, err = os.Stat(configDir)
-	if os.IsNotExist(err) {
-		return config, fmt.Errorf("config directory not found: %s", configDir)
-	}
-
-	viper.AddConfigPath(configDir)
-	viper.SetConfigName("sequencer")
-	viper.SetConfigType("toml")
-
-	if err = viper.ReadInConfig(); err != nil {
-		return config, err
-	}
-
-	err = viper.Unmarshal(&config)
-	return config, err
-}
@@ -0,0 +1,170 @@
+package blocksync
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/airchains-network/decentralized-sequencer/config"
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/airchains-network/decentralized-sequencer/store"
+	"github.com/airchains-network/decentralized-sequencer/types"
+)
+
+// checkDbConsistency looks for state torn by a crash between related writes.
+// Counters that lag behind records already written are moved forward, and
+// EVM transactions stored for a block that was never saved are dropped.
+// Counters that point at records which do not exist are an error.
+func checkDbConsistency(s store.Store) error {
+	txnCount, err := checkTxnCount(s.Txns())
+	if err != nil {
+		return err
+	}
+	if err = checkPodCounters(s.Static(), s.Pods(), s.PodState(), txnCount); err != nil {
+		return err
+	}
+	return checkEVMBlockTail(s.Blocks(), s.Txns())
+}
+
+// checkTxnCount moves txnCount past txns-N records written after it and
+// makes sure the record it points at exists.
+func checkTxnCount(txDB store.Namespace) (int, error) {
+	txnCount, err := txDB.Counter("txnCount")
+	if err != nil {
+		return 0, err
+	}
+
+	stored := txnCount
+	for {
+		found, err := txDB.Has([]byte(fmt.Sprintf("txns-%d", txnCount+1)))
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			break
+		}
+		txnCount++
+	}
+	if txnCount != stored {
+		logs.Log.Warn(fmt.Sprintf("txnCount was %d but transactions up to txns-%d are stored, repairing", stored, txnCount))
+		if err = txDB.Put([]byte("txnCount"), []byte(strconv.Itoa(txnCount))); err != nil {
+			return 0, fmt.Errorf("error repairing txnCount: %v", err)
+		}
+	}
+
+	if txnCount > 0 {
+		found, err := txDB.Has([]byte(fmt.Sprintf("txns-%d", txnCount)))
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			return 0, fmt.Errorf("txnCount is %d but txns-%d is missing", txnCount, txnCount)
+		}
+	}
+	return txnCount, nil
+}
+
+// checkPodCounters moves batchCount past pods saved after it, realigns
+// batchStartIndex with batchCount and makes sure both point at stored data.
+func checkPodCounters(staticDB store.Namespace, batchesDB store.Namespace, stateDB store.Namespace, txnCount int) error {
+	batchCount, err := staticDB.Counter("batchCount")
+	if err != nil {
+		return err
+	}
+	batchStartIndex, err := staticDB.Counter("batchStartIndex")
+	if err != nil {
+		return err
+	}
+
+	stored := batchCount
+	for {
+		found, err := batchesDB.Has([]byte(fmt.Sprintf("pod-%d", batchCount+1)))
+		if err != nil {
+			return err
+		}
+		if !found {
+			break
+		}
+		batchCount++
+	}
+	if batchCount > 0 {
+		found, err := batchesDB.Has([]byte(fmt.Sprintf("pod-%d", batchCount)))
+		if err != nil {
+			return err
+		}
+		if !found {
+			if err = restorePodFromState(batchesDB, stateDB, batchCount); err != nil {
+				return err
+			}
+		}
+	}
+
+	if batchCount != stored || batchStartIndex != config.PODSize*batchCount {
+		logs.Log.Warn(fmt.Sprintf("batchCount %d and batchStartIndex %d do not match the saved pods, repairing to %d and %d", stored, batchStartIndex, batchCount, config.PODSize*batchCount))
+		batchStartIndex = config.PODSize * batchCount
+		counters := new(store.Batch)
+		counters.Put(staticDB, []byte("batchCount"), []byte(strconv.Itoa(batchCount)))
+		counters.Put(staticDB, []byte("batchStartIndex"), []byte(strconv.Itoa(batchStartIndex)))
+		if err = staticDB.Write(counters); err != nil {
+			return fmt.Errorf("error repairing pod counters: %v", err)
+		}
+	}
+
+	if batchStartIndex > txnCount {
+		return fmt.Errorf("pods cover %d transactions but only %d are stored", batchStartIndex, txnCount)
+	}
+	return nil
+}
+
+// restorePodFromState rewrites a missing pod-N from the pod state, which still
+// holds pod N when the counters were saved but the pod itself was not.
+func restorePodFromState(batchesDB store.Namespace, stateDB store.Namespace, podNumber int) error {
+	missing := fmt.Errorf("batchCount is %d but pod-%d is missing", podNumber, podNumber)
+	data, err := stateDB.Get([]byte("podState"))
+	if err != nil {
+		return missing
+	}
+	var podState types.PodState
+	if err = json.Unmarshal(data, &podState); err != nil || podState.LatestPodHeight != uint64(podNumber) {
+		return missing
+	}
+
+	logs.Log.Warn(fmt.Sprintf("pod-%d is missing, restoring it from the pod state", podNumber))
+	if err = batchesDB.Put([]byte(fmt.Sprintf("pod-%d", podNumber)), data); err != nil {
+		return fmt.Errorf("error restoring pod-%d: %v", podNumber, err)
+	}
+	return nil
+}
+
+// checkEVMBlockTail drops EVM transactions stored for blocks at or past
+// blockCount. They belong to a block whose own write did not complete and
+// would be stored a second time when the block is indexed again.
+func checkEVMBlockTail(blockDB store.Namespace, txDB store.Namespace) error {
+	blockCount, err := blockDB.Counter("blockCount")
+	if err != nil || blockCount == 0 {
+		return err
+	}
+	// only EVM stations store blocks as block_N
+	if found, err := blockDB.Has([]byte(fmt.Sprintf("block_%d", blockCount-1))); err != nil || !found {
+		return err
+	}
+
+	txnCount, err := txDB.Counter("txnCount")
+	if err != nil || txnCount == 0 {
+		return err
+	}
+	data, err := txDB.Get([]byte(fmt.Sprintf("txns-%d", txnCount)))
+	if err != nil {
+		return err
+	}
+	var txn types.TransactionStruct
+	if err = json.Unmarshal(data, &txn); err != nil {
+		return fmt.Errorf("error decoding txns-%d: %v", txnCount, err)
+	}
+	if txn.BlockNumber < uint64(blockCount) {
+		return nil
+	}
+
+	logs.Log.Warn(fmt.Sprintf("Transactions of block %d are stored but the block is not, removing them", txn.BlockNumber))
+	return unwindEVMBlocks(blockDB, txDB, blockCount-1, blockCount)
+}
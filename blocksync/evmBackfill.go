@@ -0,0 +1,199 @@
+package blocksync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/airchains-network/decentralized-sequencer/store"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// evmBackfillBatchSize is the number of blocks fetched by one batched
+	// eth_getBlockByNumber request during backfill.
+	evmBackfillBatchSize = 20
+
+	// evmReceiptBatchSize keeps receipt batches under the default request
+	// limit of geth based nodes.
+	evmReceiptBatchSize = 500
+
+	defaultEVMBackfillWorkers = 4
+)
+
+// errBackfillDiverged stops backfill when a fetched block does not build on
+// the block stored before it. The live indexer then resolves the reorg.
+var errBackfillDiverged = errors.New("backfill diverged from stored chain")
+
+// evmBlockBundle is a block fetched during backfill with the receipts of its
+// transactions. hash is the hash the RPC reports for the block: Ethermint
+// based stations do not hash their headers the way geth recomputes them.
+type evmBlockBundle struct {
+	block    *gethTypes.Block
+	hash     string
+	receipts []*gethTypes.Receipt
+}
+
+// backfillEVM indexes blocks blockIndex to target (inclusive). Each round
+// fetches workers ranges of evmBackfillBatchSize blocks concurrently and then
+// writes them to the database in block order. It returns the next block to
+// index.
+func backfillEVM(ctx context.Context, client *ethclient.Client, signer gethTypes.Signer, blockIndex int, target int, workers int, ldb store.Namespace, ldt store.Namespace) (int, error) {
+	if workers <= 0 {
+		workers = defaultEVMBackfillWorkers
+	}
+	rpcClient := client.Client()
+
+	lastHash, haveLast := "", false
+	if blockIndex > 0 {
+		lastHash, haveLast = storedEVMBlockHash(ldb, blockIndex-1)
+	}
+
+	log.Info().Str("module", "blocksync").Msg(fmt.Sprintf("Backfilling blocks %d to %d with %d workers", blockIndex, target, workers))
+	for blockIndex <= target {
+		if ctx.Err() != nil {
+			return blockIndex, ctx.Err()
+		}
+
+		var (
+			wg      sync.WaitGroup
+			bundles = make([][]evmBlockBundle, workers)
+			errs    = make([]error, workers)
+		)
+		for w := 0; w < workers; w++ {
+			from := blockIndex + w*evmBackfillBatchSize
+			if from > target {
+				break
+			}
+			to := from + evmBackfillBatchSize - 1
+			if to > target {
+				to = target
+			}
+			wg.Add(1)
+			go func(w, from, to int) {
+				defer wg.Done()
+				bundles[w], errs[w] = fetchEVMBlocks(ctx, rpcClient, from, to)
+			}(w, from, to)
+		}
+		wg.Wait()
+
+		for w := range bundles {
+			if errs[w] != nil {
+				return blockIndex, errs[w]
+			}
+			for _, bundle := range bundles[w] {
+				if haveLast && bundle.block.ParentHash().String() != lastHash {
+					return blockIndex, errBackfillDiverged
+				}
+				if err := writeEVMBlock(ldb, ldt, signer, bundle.block, bundle.hash, bundle.receipts); err != nil {
+					return blockIndex, err
+				}
+				lastHash, haveLast = bundle.hash, true
+				blockIndex++
+			}
+		}
+		log.Debug().Str("module", "blocksync").Msg(fmt.Sprintf("Backfilled up to block %d", blockIndex-1))
+	}
+	return blockIndex, nil
+}
+
+// fetchEVMBlocks fetches blocks from to to (inclusive) with full transactions
+// in one batched request, followed by batched receipt requests.
+func fetchEVMBlocks(ctx context.Context, rpcClient *rpc.Client, from int, to int) ([]evmBlockBundle, error) {
+	raws := make([]json.RawMessage, to-from+1)
+	elems := make([]rpc.BatchElem, len(raws))
+	for i := range elems {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(uint64(from + i)), true},
+			Result: &raws[i],
+		}
+	}
+	if err := rpcClient.BatchCallContext(ctx, elems); err != nil {
+		return nil, fmt.Errorf("error fetching blocks %d to %d: %v", from, to, err)
+	}
+
+	bundles := make([]evmBlockBundle, len(raws))
+	var transactions gethTypes.Transactions
+	for i, elem := range elems {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("error fetching block %d: %v", from+i, elem.Error)
+		}
+		block, hash, err := decodeEVMBlock(raws[i])
+		if err != nil {
+			return nil, fmt.Errorf("error decoding block %d: %v", from+i, err)
+		}
+		bundles[i].block, bundles[i].hash = block, hash
+		transactions = append(transactions, block.Transactions()...)
+	}
+
+	receipts, err := fetchEVMReceipts(ctx, rpcClient, transactions)
+	if err != nil {
+		return nil, err
+	}
+	for i := range bundles {
+		count := bundles[i].block.Transactions().Len()
+		bundles[i].receipts, receipts = receipts[:count], receipts[count:]
+	}
+	return bundles, nil
+}
+
+// decodeEVMBlock decodes an eth_getBlockByNumber result fetched with full
+// transactions, and returns it with the hash the RPC reports for it.
+func decodeEVMBlock(raw json.RawMessage) (*gethTypes.Block, string, error) {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, "", errBlockNotReady
+	}
+	var header gethTypes.Header
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, "", err
+	}
+	var body struct {
+		Hash         common.Hash              `json:"hash"`
+		Transactions []*gethTypes.Transaction `json:"transactions"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, "", err
+	}
+	return gethTypes.NewBlockWithHeader(&header).WithBody(body.Transactions, nil), body.Hash.String(), nil
+}
+
+// fetchEVMReceipts fetches the receipts of transactions with batched
+// eth_getTransactionReceipt requests, in the order of transactions.
+func fetchEVMReceipts(ctx context.Context, rpcClient *rpc.Client, transactions gethTypes.Transactions) ([]*gethTypes.Receipt, error) {
+	receipts := make([]*gethTypes.Receipt, len(transactions))
+	for start := 0; start < len(transactions); start += evmReceiptBatchSize {
+		end := start + evmReceiptBatchSize
+		if end > len(transactions) {
+			end = len(transactions)
+		}
+		elems := make([]rpc.BatchElem, end-start)
+		for i := range elems {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getTransactionReceipt",
+				Args:   []interface{}{transactions[start+i].Hash()},
+				Result: &receipts[start+i],
+			}
+		}
+		if err := rpcClient.BatchCallContext(ctx, elems); err != nil {
+			return nil, fmt.Errorf("error fetching receipts: %v", err)
+		}
+		for i, elem := range elems {
+			if elem.Error != nil {
+				return nil, fmt.Errorf("error fetching receipt of %s: %v", transactions[start+i].Hash().Hex(), elem.Error)
+			}
+			if receipts[start+i] == nil {
+				return nil, fmt.Errorf("receipt of %s not found", transactions[start+i].Hash().Hex())
+			}
+		}
+	}
+	return receipts, nil
+}
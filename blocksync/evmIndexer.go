@@ -0,0 +1,176 @@
+package blocksync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/airchains-network/decentralized-sequencer/store"
+	"github.com/ethereum/go-ethereum"
+	gethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultEVMPollInterval = 3 * time.Second
+	minEVMRetryBackoff     = 1 * time.Second
+	maxEVMRetryBackoff     = 1 * time.Minute
+)
+
+// errBlockNotReady is returned by storeEVMBlock when the next block has not
+// been produced, or is not yet deep enough to be indexed.
+var errBlockNotReady = errors.New("block not ready")
+
+// RunEVMIndexer indexes EVM blocks starting at blockIndex until ctx is
+// cancelled. A station far ahead of blockIndex is first backfilled with
+// concurrent batched requests. While caught up it waits for the next head,
+// either by polling StationRPC every PollInterval or, when StationWS is set,
+// through an eth_subscribe newHeads subscription. Failures are retried with
+// exponential backoff.
+func RunEVMIndexer(ctx context.Context, client *ethclient.Client, blockIndex int, ldb store.Namespace, ldt store.Namespace, station *config.StationConfig) {
+	zerolog.TimeFieldFormat = time.RFC3339
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	pollInterval := station.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultEVMPollInterval
+	}
+
+	heads := newHeadWatcher(station.StationWS, pollInterval)
+	defer heads.close()
+
+	signer, err := evmSigner(ctx, client)
+	if err != nil {
+		return
+	}
+
+	// catch up on history with concurrent batched fetches before following
+	// the chain block by block
+	if head, err := client.BlockNumber(ctx); err == nil && head >= station.ConfirmationDepth {
+		target := int(head - station.ConfirmationDepth)
+		if target-blockIndex > evmBackfillBatchSize {
+			blockIndex, err = backfillEVM(ctx, client, signer, blockIndex, target, station.BackfillWorkers, ldb, ldt)
+			if err != nil && ctx.Err() == nil {
+				log.Warn().Str("module", "blocksync").Err(err).Msg(fmt.Sprintf("Backfill stopped at block %d, continuing block by block", blockIndex))
+			}
+		}
+	}
+
+	backoff := minEVMRetryBackoff
+	for ctx.Err() == nil {
+		next, err := storeEVMBlock(ctx, client, signer, blockIndex, ldb, ldt, station.ConfirmationDepth)
+		switch {
+		case err == nil:
+			blockIndex = next
+			backoff = minEVMRetryBackoff
+		case errors.Is(err, errBlockNotReady):
+			backoff = minEVMRetryBackoff
+			heads.wait(ctx)
+		case ctx.Err() != nil:
+		default:
+			log.Warn().Str("module", "blocksync").Err(err).Msg(fmt.Sprintf("Error indexing block %d, retrying in %s", blockIndex, backoff))
+			sleepContext(ctx, backoff)
+			if backoff *= 2; backoff > maxEVMRetryBackoff {
+				backoff = maxEVMRetryBackoff
+			}
+		}
+	}
+	log.Info().Str("module", "blocksync").Msg(fmt.Sprintf("EVM indexer stopped at block %d", blockIndex))
+}
+
+// evmSigner returns the signer used to recover transaction senders, retrying
+// until the station reports its chain id or ctx is cancelled.
+func evmSigner(ctx context.Context, client *ethclient.Client) (gethTypes.Signer, error) {
+	backoff := minEVMRetryBackoff
+	for {
+		chainID, err := client.ChainID(ctx)
+		if err == nil {
+			return gethTypes.LatestSignerForChainID(chainID), nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Warn().Str("module", "blocksync").Err(err).Msg(fmt.Sprintf("Failed to get the chain ID, retrying in %s", backoff))
+		sleepContext(ctx, backoff)
+		if backoff *= 2; backoff > maxEVMRetryBackoff {
+			backoff = maxEVMRetryBackoff
+		}
+	}
+}
+
+// headWatcher waits for the station to produce a new block.
+type headWatcher struct {
+	wsURL        string
+	pollInterval time.Duration
+
+	wsClient *ethclient.Client
+	sub      ethereum.Subscription
+	heads    chan *gethTypes.Header
+}
+
+func newHeadWatcher(wsURL string, pollInterval time.Duration) *headWatcher {
+	return &headWatcher{wsURL: wsURL, pollInterval: pollInterval}
+}
+
+// wait returns once a new head is announced or ctx is cancelled. Without a
+// working subscription it sleeps for pollInterval instead.
+func (w *headWatcher) wait(ctx context.Context) {
+	if w.wsURL != "" && w.sub == nil {
+		if err := w.subscribe(ctx); err != nil {
+			log.Warn().Str("module", "blocksync").Err(err).Msg("newHeads subscription failed, falling back to polling")
+		}
+	}
+	if w.sub == nil {
+		sleepContext(ctx, w.pollInterval)
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-w.heads:
+	case err := <-w.sub.Err():
+		log.Warn().Str("module", "blocksync").Err(err).Msg("newHeads subscription dropped")
+		w.close()
+	}
+}
+
+func (w *headWatcher) subscribe(ctx context.Context) error {
+	wsClient, err := ethclient.DialContext(ctx, w.wsURL)
+	if err != nil {
+		return err
+	}
+	heads := make(chan *gethTypes.Header, 16)
+	sub, err := wsClient.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		wsClient.Close()
+		return err
+	}
+	w.wsClient, w.sub, w.heads = wsClient, sub, heads
+	return nil
+}
+
+func (w *headWatcher) close() {
+	if w.sub != nil {
+		w.sub.Unsubscribe()
+		w.sub = nil
+	}
+	if w.wsClient != nil {
+		w.wsClient.Close()
+		w.wsClient = nil
+	}
+}
+
+// sleepContext sleeps for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,125 @@
+package blocksync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/airchains-network/decentralized-sequencer/store"
+	"github.com/airchains-network/decentralized-sequencer/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/rs/zerolog/log"
+)
+
+// storedEVMBlockHash returns the hash stored for block_<blockIndex>.
+func storedEVMBlockHash(ldb store.Namespace, blockIndex int) (string, bool) {
+	data, err := ldb.Get([]byte(fmt.Sprintf("block_%d", blockIndex)))
+	if err != nil {
+		return "", false
+	}
+	var block types.BlockStruct
+	if err = json.Unmarshal(data, &block); err != nil {
+		return "", false
+	}
+	return block.Hash, true
+}
+
+// checkEVMParent compares parentHash of block blockIndex with the hash stored
+// for the block before it. When they differ the station has reorged: the
+// diverging blocks and their transactions are unwound back to the common
+// ancestor and the height indexing must resume from is returned. Otherwise
+// blockIndex is returned unchanged.
+func checkEVMParent(client *ethclient.Client, ctx context.Context, ldb store.Namespace, ldt store.Namespace, blockIndex int, parentHash string) (int, error) {
+	if blockIndex == 0 {
+		return blockIndex, nil
+	}
+	storedHash, found := storedEVMBlockHash(ldb, blockIndex-1)
+	if !found || storedHash == parentHash {
+		return blockIndex, nil
+	}
+
+	// walk back until the stored block matches the canonical chain again
+	ancestor := blockIndex - 1
+	for ancestor >= 0 {
+		storedHash, found = storedEVMBlockHash(ldb, ancestor)
+		if !found {
+			break
+		}
+		// compare with the hash the RPC reports, as it was stored
+		var header struct {
+			Hash common.Hash `json:"hash"`
+		}
+		err := client.Client().CallContext(ctx, &header, "eth_getBlockByNumber", hexutil.EncodeUint64(uint64(ancestor)), false)
+		if err != nil {
+			return blockIndex, fmt.Errorf("error fetching header %d while resolving reorg: %v", ancestor, err)
+		}
+		if header.Hash.String() == storedHash {
+			break
+		}
+		ancestor--
+	}
+
+	log.Warn().Str("module", "blocksync").Msg(fmt.Sprintf("Reorg detected at block %d, unwinding to block %d", blockIndex, ancestor))
+	if err := unwindEVMBlocks(ldb, ldt, ancestor, blockIndex); err != nil {
+		return blockIndex, err
+	}
+	return ancestor + 1, nil
+}
+
+// unwindEVMBlocks deletes block_N for every block after ancestor up to (not
+// including) blockIndex, along with the txns-N and receipt-N records of those
+// blocks and their index entries, and rewinds blockCount and txnCount.
+// Transactions already taken into a pod can not be unwound.
+func unwindEVMBlocks(ldb store.Namespace, ldt store.Namespace, ancestor int, blockIndex int) error {
+	txnCount, err := ldt.Counter("txnCount")
+	if err != nil {
+		return err
+	}
+	committed := 0
+	if s := GetStore(); s != nil {
+		if committed, err = s.Static().Counter("batchStartIndex"); err != nil {
+			return err
+		}
+	}
+
+	batch := new(store.Batch)
+	for ; txnCount > 0; txnCount-- {
+		data, err := ldt.Get([]byte(fmt.Sprintf("txns-%d", txnCount)))
+		if err != nil {
+			return fmt.Errorf("error reading txns-%d while unwinding: %v", txnCount, err)
+		}
+		var txn types.TransactionStruct
+		if err = json.Unmarshal(data, &txn); err != nil {
+			return fmt.Errorf("error decoding txns-%d while unwinding: %v", txnCount, err)
+		}
+		if ancestor >= 0 && txn.BlockNumber <= uint64(ancestor) {
+			break
+		}
+		if txnCount <= committed {
+			return fmt.Errorf("reorg to block %d reaches txns-%d which is already part of a pod", ancestor, txnCount)
+		}
+		batch.Delete(ldt, []byte(fmt.Sprintf("txns-%d", txnCount)))
+		batch.Delete(ldt, []byte(fmt.Sprintf("receipt-%d", txnCount)))
+		batch.Delete(ldt, txHashIndexKey(txn.Hash))
+		batch.Delete(ldt, txAddrIndexKey(txn.From, txnCount))
+		batch.Delete(ldt, txAddrIndexKey(txn.To, txnCount))
+	}
+	batch.Put(ldt, []byte("txnCount"), []byte(strconv.Itoa(txnCount)))
+	if indexed, err := ldt.Counter(txIndexCountKey); err == nil && indexed > txnCount {
+		batch.Put(ldt, []byte(txIndexCountKey), []byte(strconv.Itoa(txnCount)))
+	}
+
+	for i := ancestor + 1; i < blockIndex; i++ {
+		batch.Delete(ldb, []byte(fmt.Sprintf("block_%d", i)))
+	}
+	batch.Put(ldb, []byte("blockCount"), []byte(strconv.Itoa(ancestor+1)))
+
+	// blocks and transactions share the store, so they are unwound together
+	if err = ldb.Write(batch); err != nil {
+		return fmt.Errorf("error unwinding blocks: %v", err)
+	}
+	return nil
+}
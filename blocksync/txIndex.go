@@ -0,0 +1,194 @@
+package blocksync
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/airchains-network/decentralized-sequencer/store"
+	stationTypes "github.com/airchains-network/decentralized-sequencer/types"
+	"github.com/airchains-network/decentralized-sequencer/types/svmTypes"
+)
+
+// Secondary indexes kept in the tx namespace next to txns-N:
+//
+//	txhash-<hash>          -> N
+//	txaddr-<address>-<N>   -> empty, one entry per sender and recipient
+//	txIndexCount           -> highest N covered by the indexes
+const (
+	txHashIndexPrefix = "txhash-"
+	txAddrIndexPrefix = "txaddr-"
+	txIndexCountKey   = "txIndexCount"
+
+	zeroEVMAddress = "0x0000000000000000000000000000000000000000"
+
+	txIndexBackfillBatch = 1000
+)
+
+// normalizeIndexValue lower cases hex hashes and addresses so lookups are case
+// insensitive. Base58 (SVM) values are case sensitive and kept as they are.
+func normalizeIndexValue(value string) string {
+	digits := strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X")
+	if digits != "" && strings.Trim(digits, "0123456789abcdefABCDEF") == "" {
+		return strings.ToLower(value)
+	}
+	return value
+}
+
+func txHashIndexKey(hash string) []byte {
+	return []byte(txHashIndexPrefix + normalizeIndexValue(hash))
+}
+
+func txAddrIndexPrefixKey(address string) []byte {
+	return []byte(txAddrIndexPrefix + normalizeIndexValue(address) + "-")
+}
+
+// txAddrIndexKey zero pads N so the entries of an address iterate in
+// transaction order.
+func txAddrIndexKey(address string, transactionNumber int) []byte {
+	return append(txAddrIndexPrefixKey(address), fmt.Sprintf("%020d", transactionNumber)...)
+}
+
+// putTxnIndexes adds the hash and address index entries of txns-<N> to batch.
+func putTxnIndexes(batch *store.Batch, ldt store.Namespace, transactionNumber int, hash string, addresses ...string) {
+	if hash != "" {
+		batch.Put(ldt, txHashIndexKey(hash), []byte(strconv.Itoa(transactionNumber)))
+	}
+	seen := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		address = normalizeIndexValue(address)
+		if address == "" || address == zeroEVMAddress || seen[address] {
+			continue
+		}
+		seen[address] = true
+		batch.Put(ldt, txAddrIndexKey(address, transactionNumber), nil)
+	}
+	batch.Put(ldt, []byte(txIndexCountKey), []byte(strconv.Itoa(transactionNumber)))
+}
+
+// indexStoredTxn adds the index entries of the stored txns-<N> record data to
+// batch. A record that can not be decoded is only counted as indexed, so it
+// never blocks storing the transaction itself.
+func indexStoredTxn(batch *store.Batch, ldt store.Namespace, stationType string, data []byte, transactionNumber int) {
+	hash, addresses, err := txnIndexFields(stationType, data)
+	if err != nil {
+		logs.Log.Warn(fmt.Sprintf("Can not index txns-%d: %s", transactionNumber, err.Error()))
+	}
+	putTxnIndexes(batch, ldt, transactionNumber, hash, addresses...)
+}
+
+// txnIndexFields returns the hash and the sender and recipient addresses of a
+// stored txns-N record.
+func txnIndexFields(stationType string, data []byte) (string, []string, error) {
+	switch strings.ToLower(stationType) {
+	case "evm":
+		var txn stationTypes.TransactionStruct
+		if err := json.Unmarshal(data, &txn); err != nil {
+			return "", nil, err
+		}
+		return txn.Hash, []string{txn.From, txn.To}, nil
+	case "wasm":
+		var txn stationTypes.BatchTransaction
+		if err := json.Unmarshal(data, &txn); err != nil {
+			return "", nil, err
+		}
+		var addresses []string
+		for _, msg := range txn.Tx.Body.Messages {
+			addresses = append(addresses, msg.FromAddress, msg.ToAddress)
+		}
+		return txn.TxResponse.TxHash, addresses, nil
+	case "svm":
+		var txn svmTypes.SVMTransactionStruct
+		if err := json.Unmarshal(data, &txn); err != nil {
+			return "", nil, err
+		}
+		var hash string
+		if len(txn.Transaction.Signatures) > 0 {
+			hash = txn.Transaction.Signatures[0]
+		}
+		var addresses []string
+		for _, key := range txn.Transaction.Message.AccountKeys {
+			if key.Signer || key.Writable {
+				addresses = append(addresses, key.Pubkey)
+			}
+		}
+		return hash, addresses, nil
+	default:
+		return "", nil, fmt.Errorf("unknown station type %q", stationType)
+	}
+}
+
+// BackfillTxnIndexes builds the secondary indexes for txns-N records stored
+// before they existed, resuming from txIndexCount.
+func BackfillTxnIndexes(db store.Namespace, stationType string) error {
+	indexed, err := db.Counter(txIndexCountKey)
+	if err != nil {
+		return err
+	}
+	txnCount, err := db.Counter("txnCount")
+	if err != nil {
+		return err
+	}
+	if indexed >= txnCount {
+		return nil
+	}
+
+	logs.Log.Info(fmt.Sprintf("Indexing transactions %d to %d", indexed+1, txnCount))
+	batch := new(store.Batch)
+	for n := indexed + 1; n <= txnCount; n++ {
+		data, err := db.Get([]byte(fmt.Sprintf("txns-%d", n)))
+		if err != nil {
+			return fmt.Errorf("error reading txns-%d: %v", n, err)
+		}
+		indexStoredTxn(batch, db, stationType, data, n)
+
+		if batch.Len() >= txIndexBackfillBatch || n == txnCount {
+			if err = db.Write(batch); err != nil {
+				return fmt.Errorf("error writing transaction indexes: %v", err)
+			}
+			batch.Reset()
+		}
+	}
+	return nil
+}
+
+// GetTxnNumberByHash returns N of the txns-N record with the given hash.
+func GetTxnNumberByHash(db store.Namespace, hash string) (int, error) {
+	data, err := db.Get(txHashIndexKey(hash))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(data))
+}
+
+// GetTxnNumbersByAddress returns, in transaction order, up to limit txns-N
+// numbers sent or received by address, skipping the first offset.
+func GetTxnNumbersByAddress(db store.Namespace, address string, offset int, limit int) ([]int, error) {
+	prefix := txAddrIndexPrefixKey(address)
+
+	var numbers []int
+	var parseErr error
+	skipped := 0
+	err := db.Iterate(prefix, func(key []byte, _ []byte) bool {
+		if len(numbers) >= limit {
+			return false
+		}
+		if skipped < offset {
+			skipped++
+			return true
+		}
+		n, err := strconv.Atoi(string(key[len(prefix):]))
+		if err != nil {
+			parseErr = fmt.Errorf("invalid address index key %q: %v", key, err)
+			return false
+		}
+		numbers = append(numbers, n)
+		return true
+	})
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	return numbers, err
+}
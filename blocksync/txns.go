@@ -1,7 +1,6 @@
 package blocksync
 
 import (
-	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
@@ -16,118 +15,121 @@ import (
 	"time"
 
 	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/airchains-network/decentralized-sequencer/store"
 	stationTypes "github.com/airchains-network/decentralized-sequencer/types"
 	"github.com/airchains-network/decentralized-sequencer/types/svmTypes"
 	utilis "github.com/airchains-network/decentralized-sequencer/utils"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
-func insertTxnEVM(db *leveldb.DB, txns stationTypes.TransactionStruct, transactionNumber int) error {
+func insertTxnEVM(batch *store.Batch, ldt store.Namespace, txns stationTypes.TransactionStruct, receipt stationTypes.ReceiptStruct, transactionNumber int) error {
 	data, err := json.Marshal(txns)
 	if err != nil {
 		return err
 	}
-
-	txnsKey := fmt.Sprintf("txns-%d", transactionNumber+1)
-	err = db.Put([]byte(txnsKey), data, nil)
+	receiptData, err := json.Marshal(receipt)
 	if err != nil {
 		return err
 	}
 
-	err = db.Put([]byte("txnCount"), []byte(strconv.Itoa(transactionNumber+1)), nil)
-	if err != nil {
-		return err
-	}
+	txnsKey := fmt.Sprintf("txns-%d", transactionNumber+1)
+	batch.Put(ldt, []byte(txnsKey), data)
+	batch.Put(ldt, []byte(fmt.Sprintf("receipt-%d", transactionNumber+1)), receiptData)
+	batch.Put(ldt, []byte("txnCount"), []byte(strconv.Itoa(transactionNumber+1)))
+	putTxnIndexes(batch, ldt, transactionNumber+1, txns.Hash, txns.From, txns.To)
 
 	return nil
 }
 
-func insertTxnWASM(db *leveldb.DB, txns []byte, transactionNumber int) error {
-
-	txnsKey := fmt.Sprintf("txns-%d", transactionNumber+1)
-	err := db.Put([]byte(txnsKey), txns, nil)
+// GetEVMReceipt returns the receipt stored for txns-<transactionNumber>.
+// store.ErrNotFound is returned for transactions indexed before receipts
+// were recorded.
+func GetEVMReceipt(db store.Namespace, transactionNumber int) (*stationTypes.ReceiptStruct, error) {
+	data, err := db.Get([]byte(fmt.Sprintf("receipt-%d", transactionNumber)))
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	err = db.Put([]byte("txnCount"), []byte(strconv.Itoa(transactionNumber+1)), nil)
-	if err != nil {
-		return err
+	var receipt stationTypes.ReceiptStruct
+	if err = json.Unmarshal(data, &receipt); err != nil {
+		return nil, err
 	}
+	return &receipt, nil
+}
 
-	return nil
+func insertTxnWASM(db store.Namespace, txns []byte, transactionNumber int) error {
+	batch := new(store.Batch)
+	txnsKey := fmt.Sprintf("txns-%d", transactionNumber+1)
+	batch.Put(db, []byte(txnsKey), txns)
+	batch.Put(db, []byte("txnCount"), []byte(strconv.Itoa(transactionNumber+1)))
+
+	indexStoredTxn(batch, db, "wasm", txns, transactionNumber+1)
+
+	return db.Write(batch)
 }
 
-func insertTxnSVM(db *leveldb.DB, txn svmTypes.SVMTransactionStruct, transactionNumber int) error {
+func insertTxnSVM(db store.Namespace, txn svmTypes.SVMTransactionStruct, transactionNumber int) error {
 	data, err := json.Marshal(txn)
 	if err != nil {
 		return err
 	}
 
+	batch := new(store.Batch)
 	txnsKey := fmt.Sprintf("txns-%d", transactionNumber+1)
-	if err := db.Put([]byte(txnsKey), data, nil); err != nil {
-		return err
-	}
+	batch.Put(db, []byte(txnsKey), data)
+	batch.Put(db, []byte("txnCount"), []byte(strconv.Itoa(transactionNumber+1)))
 
-	err = db.Put([]byte("txnCount"), []byte(strconv.Itoa(transactionNumber+1)), nil)
-	if err != nil {
-		return err
-	}
+	indexStoredTxn(batch, db, "svm", data, transactionNumber+1)
 
-	return nil
+	return db.Write(batch)
 }
 
-func StoreEVMTransactions(client *ethclient.Client, ctx context.Context, ldt *leveldb.DB, transactionHash string, blockNumber int, blockHash string) {
-	blockNumberUint64, err := strconv.ParseUint(strconv.Itoa(blockNumber), 10, 64)
-	if err != nil {
-		logs.Log.Error(fmt.Sprintf("error parsing block number to uint64:", err))
-		time.Sleep(2 * time.Second)
-		logs.Log.Info("Retrying in 2s...")
-		StoreEVMTransactions(client, ctx, ldt, transactionHash, blockNumber, blockHash)
+// evmReceiptData converts the receipt of a transaction into the record stored
+// as receipt-N.
+func evmReceiptData(receipt *types.Receipt) stationTypes.ReceiptStruct {
+	logs := make([]stationTypes.LogStruct, 0, len(receipt.Logs))
+	for _, l := range receipt.Logs {
+		topics := make([]string, len(l.Topics))
+		for i, topic := range l.Topics {
+			topics[i] = topic.Hex()
+		}
+		logs = append(logs, stationTypes.LogStruct{
+			Address:  l.Address.Hex(),
+			Topics:   topics,
+			Data:     hexutil.Encode(l.Data),
+			LogIndex: l.Index,
+		})
 	}
-	var (
-		tx        *types.Transaction
-		isPending bool
-	)
 
-	txHash := common.HexToHash(transactionHash)
-	for {
-		tx, isPending, err = client.TransactionByHash(ctx, txHash)
-		if err != nil {
-			logs.Log.Debug(fmt.Sprintf("Failed to get transaction by hash: %s", err))
-			// Retry transaction
-			log.Println("Retrying the transaction after 10 seconds...")
-			time.Sleep(time.Second * 10) // Wait for 10 seconds before retrying
-			continue
-		}
-		if isPending {
-			logs.Log.Debug("Transaction is pending, waiting for 5 seconds for tx Approval in blockchain")
-			logs.Log.Info(fmt.Sprintf("Transaction type: %d\n", tx.Type()))
-			// Retry after 5 seconds if transaction is pending
-			time.Sleep(time.Second * 5)
-			continue
-		}
-		break
+	var contractAddress string
+	if receipt.ContractAddress != (common.Address{}) {
+		contractAddress = receipt.ContractAddress.Hex()
 	}
 
-	chainID, err := client.NetworkID(context.Background())
-	if err != nil {
-		logs.Log.Error(fmt.Sprintf("Failed to get the network ID: %v", err))
-		os.Exit(0)
+	var effectiveGasPrice string
+	if receipt.EffectiveGasPrice != nil {
+		effectiveGasPrice = receipt.EffectiveGasPrice.String()
 	}
-	msg, err := types.Sender(types.NewLondonSigner(chainID), tx)
-	if err != nil {
-		logs.Log.Error(fmt.Sprintf("Failed to derive the sender address: %v", err))
-		os.Exit(0)
+
+	return stationTypes.ReceiptStruct{
+		TransactionHash:   receipt.TxHash.Hex(),
+		BlockNumber:       receipt.BlockNumber.Uint64(),
+		Status:            receipt.Status,
+		GasUsed:           utilis.ToString(receipt.GasUsed),
+		CumulativeGasUsed: utilis.ToString(receipt.CumulativeGasUsed),
+		EffectiveGasPrice: effectiveGasPrice,
+		ContractAddress:   contractAddress,
+		Logs:              logs,
 	}
+}
 
-	receipt, err := client.TransactionReceipt(context.Background(), txHash)
+// evmTransactionData converts a transaction taken from its block, and its
+// receipt, into the record stored as txns-N.
+func evmTransactionData(tx *types.Transaction, signer types.Signer, receipt *types.Receipt, blockNumber uint64, blockHash string) (stationTypes.TransactionStruct, error) {
+	msg, err := types.Sender(signer, tx)
 	if err != nil {
-		logs.Log.Error(fmt.Sprintf("Failed to fetch the transaction receipt: %v", err))
-		os.Exit(0)
+		return stationTypes.TransactionStruct{}, fmt.Errorf("failed to derive the sender address of %s: %v", tx.Hash().Hex(), err)
 	}
 
 	v, r, s := tx.RawSignatureValues()
@@ -139,9 +141,9 @@ func StoreEVMTransactions(client *ethclient.Client, ctx context.Context, ldt *le
 		toAddress = tx.To().Hex()
 	}
 
-	var txData = stationTypes.TransactionStruct{
+	return stationTypes.TransactionStruct{
 		BlockHash:        blockHash,
-		BlockNumber:      blockNumberUint64,
+		BlockNumber:      blockNumber,
 		From:             msg.Hex(),
 		Gas:              utilis.ToString(tx.Gas()),
 		GasPrice:         tx.GasPrice().String(),
@@ -155,30 +157,10 @@ func StoreEVMTransactions(client *ethclient.Client, ctx context.Context, ldt *le
 		Type:             fmt.Sprintf("%d", tx.Type()),
 		V:                v.String(),
 		Value:            tx.Value().String(),
-	}
-
-	// get transaction number from database
-	transactionNumberBytes, err := ldt.Get([]byte("txnCount"), nil)
-	if err != nil {
-		logs.Log.Error(fmt.Sprintf("Failed to get transaction number: %s" + err.Error()))
-		os.Exit(0)
-	}
-
-	transactionNumber, err := strconv.Atoi(strings.TrimSpace(string(transactionNumberBytes)))
-	if err != nil {
-		logs.Log.Error(fmt.Sprintf("Invalid transaction number : %s" + err.Error()))
-		os.Exit(0)
-	}
-
-	insetTxnErr := insertTxnEVM(ldt, txData, transactionNumber)
-	if insetTxnErr != nil {
-		logs.Log.Error(fmt.Sprintf("Failed to insert transaction: %s" + insetTxnErr.Error()))
-		os.Exit(0)
-	}
-
+	}, nil
 }
 
-func StoreWasmTransaction(txn []interface{}, db *leveldb.DB, JsonAPI string) {
+func StoreWasmTransaction(txn []interface{}, db store.Namespace, JsonAPI string) {
 	for _, tx := range txn {
 		hash, err := ComputeTransactionHash(tx.(string))
 		if err != nil {
@@ -212,7 +194,7 @@ func StoreWasmTransaction(txn []interface{}, db *leveldb.DB, JsonAPI string) {
 			}
 
 			// get transaction number from database
-			transactionNumberBytes, err := db.Get([]byte("txnCount"), nil)
+			transactionNumberBytes, err := db.Get([]byte("txnCount"))
 			if err != nil {
 				logs.Log.Error(fmt.Sprintf("Failed to get transaction number: %s" + err.Error()))
 				os.Exit(0)
@@ -245,9 +227,9 @@ func ComputeTransactionHash(base64Tx string) (string, error) {
 	return txHash, nil
 }
 
-func StoreSVMTransaction(db *leveldb.DB, txn svmTypes.SVMTransactionStruct) {
+func StoreSVMTransaction(db store.Namespace, txn svmTypes.SVMTransactionStruct) {
 	// get transaction number from database
-	transactionNumberBytes, err := db.Get([]byte("txnCount"), nil)
+	transactionNumberBytes, err := db.Get([]byte("txnCount"))
 	if err != nil {
 		logs.Log.Error(fmt.Sprintf("Failed to get transaction number: %s" + err.Error()))
 		os.Exit(0)
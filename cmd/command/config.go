@@ -0,0 +1,41 @@
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/airchains-network/decentralized-sequencer/config"
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/spf13/cobra"
+)
+
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the sequencer config",
+}
+
+var ConfigValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a sequencer config file without starting the node",
+	Run: func(cmd *cobra.Command, _ []string) {
+		path, err := cmd.Flags().GetString("file")
+		if err != nil {
+			logs.Log.Error(fmt.Sprintf("failed to get flag 'file': %v", err))
+			os.Exit(1)
+		}
+		if path == "" {
+			path = config.ConfigPath(config.DefaultConfigFileName)
+		}
+
+		conf, err := config.Load(path)
+		if err != nil {
+			logs.Log.Error(err.Error())
+			os.Exit(1)
+		}
+		if err = conf.Validate(); err != nil {
+			logs.Log.Error(fmt.Sprintf("%s: %s", path, err.Error()))
+			os.Exit(1)
+		}
+		logs.Log.Info(path + " is valid")
+	},
+}
@@ -2,10 +2,10 @@ package command
 
 import (
 	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
 	"github.com/airchains-network/decentralized-sequencer/junction"
 	junctionTypes "github.com/airchains-network/decentralized-sequencer/junction/types"
 	logs "github.com/airchains-network/decentralized-sequencer/log"
-	"github.com/airchains-network/decentralized-sequencer/node/shared"
 	"github.com/airchains-network/decentralized-sequencer/types"
 	v1 "github.com/airchains-network/decentralized-sequencer/zk/v1EVM"
 	"github.com/google/uuid"
@@ -43,7 +43,7 @@ var CreateStation = &cobra.Command{
 	Use:   "create-station",
 	Short: "Create station from generated wallet",
 	Run: func(cmd *cobra.Command, args []string) {
-		conf, err := shared.LoadConfig()
+		conf, err := config.Current()
 		if err != nil {
 			logs.Log.Error("Failed to load conf info")
 			return
@@ -3,13 +3,13 @@ package command
 import (
 	"fmt"
 	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/airchains-network/decentralized-sequencer/da"
 	logs "github.com/airchains-network/decentralized-sequencer/log"
 
 	//logs "github.com/airchains-network/decentralized-sequencer/log"
 	"github.com/airchains-network/decentralized-sequencer/p2p"
 	"github.com/spf13/cobra"
-	"os"
-	"path/filepath"
+	"strings"
 )
 
 type Configs struct {
@@ -41,14 +41,8 @@ func InitConfigs(cmd *cobra.Command) (*Configs, error) {
 		return nil, fmt.Errorf("failed to get flag 'daType': %w", err)
 	}
 
-	validTypes := map[string]bool{
-		"avail":    true,
-		"celestia": true,
-		"eigen":    true,
-		"mock":     true,
-	}
-	if _, isValid := validTypes[configs.daType]; !isValid {
-		logs.Log.Error("invalid daType. Must be one of: avail, celestia, eigen, mock")
+	if !da.IsRegistered(configs.daType) {
+		logs.Log.Error("invalid daType. Must be one of: " + strings.Join(da.Registered(), ", "))
 		return nil, fmt.Errorf("invalid daType: %s", configs.daType)
 	}
 
@@ -85,18 +79,17 @@ var InitCmd = &cobra.Command{
 			return
 		}
 
-		homeDir, err := os.UserHomeDir()
+		tracksDir := config.HomeDir()
+
+		conf := config.DefaultConfig()
+		// an existing node key is kept, so re-running init does not change
+		// the peer ID other tracks know this node by
+		_, peerID, err := p2p.LoadOrGenerateIdentity(p2p.IdentityPath())
 		if err != nil {
-			logs.Log.Error("Failed to get user home directory:" + err.Error())
+			logs.Log.Error(err.Error())
 			return
 		}
 
-		tracksDir := filepath.Join(homeDir, config.DefaultTracksDir)
-
-		conf := config.DefaultConfig()
-		peerGen := p2p.NewPeerGenerator("/ip4/0.0.0.0/tcp/2300", false)
-		peerID, err := peerGen.GeneratePeerID()
-
 		conf.BaseConfig.RootDir = tracksDir
 		conf.DA.DaType = configs.daType
 		conf.DA.DaRPC = configs.daRPC
@@ -7,6 +7,7 @@ import (
 	logger "github.com/airchains-network/decentralized-sequencer/log"
 	"github.com/airchains-network/decentralized-sequencer/node/shared"
 	"github.com/airchains-network/decentralized-sequencer/p2p"
+	"github.com/airchains-network/decentralized-sequencer/store"
 	"github.com/airchains-network/decentralized-sequencer/types"
 	"github.com/spf13/cobra"
 	"strconv"
@@ -27,9 +28,8 @@ func runRollbackCommand(_ *cobra.Command, _ []string) {
 		return
 	}
 
-	batchDB := shared.Node.NodeConnections.GetPodsDatabaseConnection()
-	staticDBConnection := shared.Node.NodeConnections.GetStaticDatabaseConnection()
-	stateConnection := shared.Node.NodeConnections.GetStateDatabaseConnection()
+	db := shared.Node.Store
+	batchDB := db.Pods()
 
 	podStateData, err := p2p.GetPodStateFromDatabase()
 	if err != nil {
@@ -45,7 +45,7 @@ func runRollbackCommand(_ *cobra.Command, _ []string) {
 	processingPodNumber := podStateData.LatestPodHeight
 	requiredPodNumberInt := int(processingPodNumber - 1)
 	podKey := fmt.Sprintf("pod-%d", requiredPodNumberInt)
-	oldPodStateByte, err := batchDB.Get([]byte(podKey), nil)
+	oldPodStateByte, err := batchDB.Get([]byte(podKey))
 	if err != nil {
 		logger.Log.Error("Error in getting old pod state data from database")
 		return
@@ -58,21 +58,14 @@ func runRollbackCommand(_ *cobra.Command, _ []string) {
 		return
 	}
 
-	err = stateConnection.Put([]byte("podState"), oldPodStateByte, nil)
+	// the pod state and the counters are rolled back together
+	batch := new(store.Batch)
+	batch.Put(db.PodState(), []byte("podState"), oldPodStateByte)
+	batch.Put(db.Static(), []byte("batchStartIndex"), []byte(strconv.Itoa(config.PODSize*(requiredPodNumberInt))))
+	batch.Put(db.Static(), []byte("batchCount"), []byte(strconv.Itoa(requiredPodNumberInt)))
+	err = db.Write(batch)
 	if err != nil {
-		logger.Log.Error("Error in updating podState in state db")
-		return
-	}
-
-	err = staticDBConnection.Put([]byte("batchStartIndex"), []byte(strconv.Itoa(config.PODSize*(requiredPodNumberInt))), nil)
-	if err != nil {
-		logger.Log.Error("Error in updating batchStartIndex in static db")
-		return
-	}
-
-	err = staticDBConnection.Put([]byte("batchCount"), []byte(strconv.Itoa(requiredPodNumberInt)), nil)
-	if err != nil {
-		logger.Log.Error("Error in updating batchCount in static db")
+		logger.Log.Error("Error in updating podState, batchStartIndex and batchCount")
 		return
 	}
 
@@ -3,13 +3,15 @@ package command
 import (
 	"errors"
 	"github.com/airchains-network/decentralized-sequencer/blocksync"
+	"github.com/airchains-network/decentralized-sequencer/config"
 	logger "github.com/airchains-network/decentralized-sequencer/log"
 	"github.com/airchains-network/decentralized-sequencer/node"
 	"github.com/airchains-network/decentralized-sequencer/node/shared"
 	"github.com/spf13/cobra"
 )
 
-func runSequencerCommand(_ *cobra.Command, _ []string) {
+func runSequencerCommand(cmd *cobra.Command, _ []string) {
+	config.SetFlagOverrides(cmd.Flags())
 	if err := initSequencer(); err != nil {
 		logger.Log.Error(err.Error())
 		logger.Log.Error("Error in initiating sequencer nodes due to the above error")
@@ -19,26 +21,33 @@ func runSequencerCommand(_ *cobra.Command, _ []string) {
 }
 
 func initSequencer() error {
-	config, err := shared.LoadConfig()
+	conf, err := config.Current()
 	if err != nil {
 		logger.Log.Error("Failed to load conf info")
 		return err
 	}
+	if err = conf.Validate(); err != nil {
+		return err
+	}
 
-	if success := blocksync.InitDb(); !success {
+	if success := blocksync.InitDb(conf.BaseConfig); !success {
 		return errors.New("failed to initialize database")
 	}
 	logger.Log.Info("Database Initialized")
 
-	if config.Junction.StationId == "" {
+	if err = blocksync.BackfillTxnIndexes(blocksync.GetStore().Txns(), conf.Station.StationType); err != nil {
+		return err
+	}
+
+	if conf.Junction.StationId == "" {
 		return errors.New("create station before stating sequencer")
 	}
 
-	if config.Junction.VRFPublicKey == "" || config.Junction.VRFPrivateKey == "" {
+	if conf.Junction.VRFPublicKey == "" || conf.Junction.VRFPrivateKey == "" {
 		return errors.New("VRF keys not setup properly")
 	}
 
-	shared.NewNode(config)
+	shared.NewNode(conf)
 	return nil
 }
 
@@ -4,6 +4,11 @@ import (
 	"github.com/airchains-network/decentralized-sequencer/cmd/command"
 	"github.com/airchains-network/decentralized-sequencer/cmd/command/keys"
 	"github.com/airchains-network/decentralized-sequencer/cmd/command/zkpCmd"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	_ "github.com/airchains-network/decentralized-sequencer/da/avail"
+	_ "github.com/airchains-network/decentralized-sequencer/da/celestia"
+	_ "github.com/airchains-network/decentralized-sequencer/da/eigen"
+	_ "github.com/airchains-network/decentralized-sequencer/da/mockda"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/spf13/cobra"
 	"os"
@@ -13,7 +18,12 @@ func main() {
 	var rootCmd = &cobra.Command{
 		Use:   "track",
 		Short: "Decentralized Sequencer for Stations",
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			home, _ := cmd.Flags().GetString("home")
+			config.SetHomeDir(home)
+		},
 	}
+	rootCmd.PersistentFlags().String("home", "", "Home directory of the Tracks (default $"+config.HomeEnvVar+" or ~/.tracks)")
 
 	rootCmd.AddCommand(command.StationCmd)
 	rootCmd.AddCommand(command.InitCmd)
@@ -21,11 +31,13 @@ func main() {
 	rootCmd.AddCommand(command.ProverGenCMD)
 	rootCmd.AddCommand(command.CreateStation)
 	rootCmd.AddCommand(command.Rollback)
+	rootCmd.AddCommand(command.ConfigCmd)
 
 	command.KeyGenCmd.AddCommand(keys.JunctionKeyGenCmd)
 	command.KeyGenCmd.AddCommand(keys.JunctionKeyImportCmd)
 	command.ProverGenCMD.AddCommand(zkpCmd.V1ZKP)
 	command.ProverGenCMD.AddCommand(zkpCmd.V1ZKPWasm)
+	command.ConfigCmd.AddCommand(command.ConfigValidateCmd)
 
 	keys.JunctionKeyGenCmd.Flags().String("accountName", "", "Account Name")
 	keys.JunctionKeyGenCmd.Flags().String("accountPath", "", "Account Path")
@@ -40,7 +52,7 @@ func main() {
 	keys.JunctionKeyImportCmd.MarkFlagRequired("mnemonic")
 
 	command.InitCmd.Flags().String("moniker", "", "Moniker for the Tracks")
-	command.InitCmd.Flags().String("stationType", "", "Station Type for the Tracks (evm | cosmwasm | svm)")
+	command.InitCmd.Flags().String("stationType", "", "Station Type for the Tracks (evm | wasm | svm)")
 	command.InitCmd.Flags().String("daType", "mock", "DA Type for the Tracks (avail | celestia | eigen | mock)")
 	command.InitCmd.Flags().String("daRpc", "", "DA RPC for the Tracks")
 	command.InitCmd.Flags().String("daKey", "", "DA Key for the Tracks")
@@ -66,6 +78,10 @@ func main() {
 	command.CreateStation.MarkFlagRequired("jsonRPC")
 	command.CreateStation.MarkFlagRequired("tracks")
 
+	config.RegisterFlags(command.StationCmd.Flags())
+
+	command.ConfigValidateCmd.Flags().String("file", "", "Config file to validate (default <home>/config/sequencer.toml)")
+
 	if err := rootCmd.Execute(); err != nil {
 		log.Error(err.Error())
 		os.Exit(1)
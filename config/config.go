@@ -77,6 +77,23 @@ func DefaultBaseConfig() *BaseConfig {
 	}
 }
 
+// DBDir returns the directory of the database. A relative DBPath is resolved
+// against RootDir, or HomeDir when RootDir is not set.
+func (cfg *BaseConfig) DBDir() string {
+	dbPath := cfg.DBPath
+	if dbPath == "" {
+		dbPath = DefaultDataDir
+	}
+	if filepath.IsAbs(dbPath) {
+		return dbPath
+	}
+	rootDir := cfg.RootDir
+	if rootDir == "" {
+		rootDir = HomeDir()
+	}
+	return filepath.Join(rootDir, dbPath)
+}
+
 type RPCConfig struct {
 	mu                        sync.RWMutex
 	RootDir                   string        `toml:"root_dir"`
@@ -137,6 +154,22 @@ type P2PConfig struct {
 	Seeds                   string   `toml:"seeds"`
 	PersistentPeers         []string `toml:"persistent_peers"`
 	CurrentlyConnectedPeers []string `toml:"currently_connected_peers"`
+
+	// MDNS finds other tracks of the station on the local network.
+	MDNS bool `toml:"mdns"`
+	// DHT finds other tracks of the station through a Kademlia DHT, using
+	// the station ID as rendezvous.
+	DHT bool `toml:"dht"`
+	// BootstrapPeers seed the DHT, as <multiaddr>/p2p/<peer ID>.
+	BootstrapPeers []string `toml:"bootstrap_peers"`
+
+	// BanThreshold is the score under which a misbehaving peer is
+	// disconnected and banned for BanDuration.
+	BanThreshold int           `toml:"ban_threshold"`
+	BanDuration  time.Duration `toml:"ban_duration"`
+	// MessageRateLimit is how many messages of each type a peer may send
+	// per minute, 0 for no limit.
+	MessageRateLimit int `toml:"message_rate_limit"`
 }
 
 func DefaultP2PConfig() *P2PConfig {
@@ -146,6 +179,13 @@ func DefaultP2PConfig() *P2PConfig {
 		ExternalAddress: "",
 		NodeId:          "",
 		PersistentPeers: []string{},
+		MDNS:            false,
+		DHT:             false,
+		BootstrapPeers:  []string{},
+
+		BanThreshold:     -100,
+		BanDuration:      time.Hour,
+		MessageRateLimit: 30,
 	}
 }
 
@@ -181,35 +221,46 @@ func NewStateSyncConfig() *StateSyncConfig {
 type ConsensusConfig struct {
 	RootDir string `mapstructure:"home"`
 
-	// Timing configurations for the pod consensus process
-	TimeoutPropose             time.Duration
-	TimeoutProposeDelta        time.Duration
-	TimeoutPrevote             time.Duration
-	TimeoutPrevoteDelta        time.Duration
-	TimeoutPrecommit           time.Duration
-	TimeoutPrecommitDelta      time.Duration
-	TimeoutCommit              time.Duration
-	SkipTimeoutCommit          bool
-	ValidatePods               bool
-	PodValidationSleepDuration time.Duration
-	DoubleSignCheckHeight      int64
+	// Timing configurations for the pod consensus process. The leader of a
+	// pod has TimeoutPropose to initiate the VRF, the selected track
+	// TimeoutPrevote to validate it, the pod is submitted within
+	// TimeoutPrecommit and verified within TimeoutCommit. When a step times
+	// out, the next track in the rotation leads the pod; every round waits
+	// one more delta than the previous one.
+	TimeoutPropose             time.Duration `toml:"pod_propose_timeout"`
+	TimeoutProposeDelta        time.Duration `toml:"pod_propose_timeout_delta"`
+	TimeoutPrevote             time.Duration `toml:"pod_prevote_timeout"`
+	TimeoutPrevoteDelta        time.Duration `toml:"pod_prevote_timeout_delta"`
+	TimeoutPrecommit           time.Duration `toml:"pod_precommit_timeout"`
+	TimeoutPrecommitDelta      time.Duration `toml:"pod_precommit_timeout_delta"`
+	TimeoutCommit              time.Duration `toml:"pod_commit_timeout"`
+	SkipTimeoutCommit          bool          `toml:"skip_timeout_commit"`
+	ValidatePods               bool          `toml:"validatePods"`
+	PodValidationSleepDuration time.Duration `toml:"pod_validation_sleep_duration"`
+	DoubleSignCheckHeight      int64         `toml:"double_sign_check_height"`
+
+	// QuorumPercent is the share of the station tracks, in percent, that
+	// must vote for the TracksAppHash of the leader before it submits the
+	// pod. Votes are only collected when ValidatePods is set.
+	QuorumPercent int `toml:"quorum_percent"`
 }
 
 // DefaultConsensusConfig returns a default configuration for the consensus service.
 func DefaultConsensusConfig() *ConsensusConfig {
 
 	return &ConsensusConfig{
-		TimeoutPropose:             3 * time.Second,
-		TimeoutProposeDelta:        500 * time.Millisecond,
-		TimeoutPrevote:             1 * time.Second,
-		TimeoutPrevoteDelta:        500 * time.Millisecond,
-		TimeoutPrecommit:           1 * time.Second,
-		TimeoutPrecommitDelta:      500 * time.Millisecond,
-		TimeoutCommit:              1 * time.Second,
+		TimeoutPropose:             time.Minute,
+		TimeoutProposeDelta:        30 * time.Second,
+		TimeoutPrevote:             time.Minute,
+		TimeoutPrevoteDelta:        30 * time.Second,
+		TimeoutPrecommit:           5 * time.Minute, // DA and junction submission
+		TimeoutPrecommitDelta:      time.Minute,
+		TimeoutCommit:              time.Minute,
 		SkipTimeoutCommit:          false,
 		ValidatePods:               true,
 		PodValidationSleepDuration: 100 * time.Millisecond,
 		DoubleSignCheckHeight:      0,
+		QuorumPercent:              66,
 	}
 }
 
@@ -217,13 +268,17 @@ type DAConfig struct {
 	DaType string
 	DaRPC  string
 	DaKey  string
+
+	// DaCompression gzips the pod blob before it is posted to the DA layer.
+	DaCompression bool
 }
 
 func DefaultDAConfig() *DAConfig {
 	return &DAConfig{
-		DaType: "",
-		DaRPC:  "",
-		DaKey:  "",
+		DaType:        "",
+		DaRPC:         "",
+		DaKey:         "",
+		DaCompression: false,
 	}
 }
 
@@ -231,14 +286,33 @@ type StationConfig struct {
 	StationType string
 	StationRPC  string
 	StationAPI  string
+
+	// StationWS is an optional websocket endpoint. When set, the EVM indexer
+	// subscribes to newHeads instead of polling StationRPC.
+	StationWS string
+
+	// PollInterval is how often the EVM indexer asks StationRPC for new blocks.
+	PollInterval time.Duration
+
+	// ConfirmationDepth is the number of blocks that must be built on top of an
+	// EVM block before blocksync indexes it.
+	ConfirmationDepth uint64
+
+	// BackfillWorkers is the number of block ranges fetched concurrently while
+	// the EVM indexer catches up on history.
+	BackfillWorkers int
 }
 
 // DefaultStationConfig returns a default configuration for the station.
 func DefaultStationConfig() *StationConfig {
 	return &StationConfig{
-		StationType: "",
-		StationRPC:  "",
-		StationAPI:  "",
+		StationType:       "",
+		StationRPC:        "",
+		StationAPI:        "",
+		StationWS:         "",
+		PollInterval:      3 * time.Second,
+		ConfirmationDepth: 0,
+		BackfillWorkers:   4,
 	}
 }
 
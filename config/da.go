@@ -0,0 +1,61 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DAConfigCheck checks the [da] section for the DA type it is registered
+// for, reporting what it finds to problems.
+type DAConfigCheck func(c *DAConfig, problems DAProblems)
+
+// DAProblems collects the problems a DAConfigCheck finds in the [da]
+// section.
+type DAProblems struct {
+	v *validator
+}
+
+// Addf reports a problem with field, such as "da.daKey".
+func (p DAProblems) Addf(field string, format string, args ...interface{}) {
+	p.v.addf(field, format, args...)
+}
+
+// URL reports field when value is not a URL with one of schemes, or is
+// empty while required.
+func (p DAProblems) URL(field string, value string, required bool, schemes ...string) {
+	p.v.url(field, value, required, schemes...)
+}
+
+var (
+	daTypesMu sync.RWMutex
+	daTypes   = make(map[string]DAConfigCheck)
+)
+
+// RegisterDAType makes daType a valid da.daType. check holds the rules of
+// that DA layer and may be nil. The da package registers each backend here,
+// so Validate accepts exactly the backends the binary is built with.
+func RegisterDAType(daType string, check DAConfigCheck) {
+	daTypesMu.Lock()
+	defer daTypesMu.Unlock()
+	daTypes[strings.ToLower(daType)] = check
+}
+
+// DATypes returns the registered DA types, sorted.
+func DATypes() []string {
+	daTypesMu.RLock()
+	defer daTypesMu.RUnlock()
+	types := make([]string, 0, len(daTypes))
+	for daType := range daTypes {
+		types = append(types, daType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func daCheck(daType string) (DAConfigCheck, bool) {
+	daTypesMu.RLock()
+	defer daTypesMu.RUnlock()
+	check, ok := daTypes[strings.ToLower(daType)]
+	return check, ok
+}
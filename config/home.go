@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// HomeEnvVar is the environment variable that overrides the default home
+// directory when --home is not given.
+const HomeEnvVar = "TRACKS_HOME"
+
+var homeDir string
+
+// SetHomeDir sets the home directory of every command, from the --home flag.
+// An empty dir falls back to TRACKS_HOME and then ~/.tracks.
+func SetHomeDir(dir string) {
+	homeDir = dir
+}
+
+// HomeDir returns the directory holding the config, keys and data of this
+// tracks node.
+func HomeDir() string {
+	if homeDir != "" {
+		return homeDir
+	}
+	if dir := os.Getenv(HomeEnvVar); dir != "" {
+		return dir
+	}
+	userHomeDir, _ := os.UserHomeDir()
+	return filepath.Join(userHomeDir, DefaultTracksDir)
+}
+
+// ConfigPath returns the path of elem inside the config directory of HomeDir.
+func ConfigPath(elem ...string) string {
+	return filepath.Join(append([]string{HomeDir(), DefaultConfigDir}, elem...)...)
+}
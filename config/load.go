@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/pelletier/go-toml"
+)
+
+// legacyConsensusKeys are the timeouts older versions of init wrote to the
+// consensus section, which were never read. Their values of a few seconds
+// are too short for a junction transaction, so they are not read now either.
+var legacyConsensusKeys = map[string]string{
+	"timeout_propose":         "pod_propose_timeout",
+	"timeout_propose_delta":   "pod_propose_timeout_delta",
+	"timeout_prevote":         "pod_prevote_timeout",
+	"timeout_prevote_delta":   "pod_prevote_timeout_delta",
+	"timeout_precommit":       "pod_precommit_timeout",
+	"timeout_precommit_delta": "pod_precommit_timeout_delta",
+	"timeout_commit":          "pod_commit_timeout",
+}
+
+var (
+	currentMu sync.Mutex
+	current   *Config
+)
+
+// Load reads the config file at path over DefaultConfig, so fields missing
+// from the file keep their defaults, and RootDir is set to HomeDir so the
+// data of the node lives in the home it is started with, even when the file
+// was copied from another home.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error in reading config file: %s : %v", path, err)
+	}
+
+	conf := DefaultConfig()
+	if err = toml.Unmarshal(data, conf); err != nil {
+		return nil, fmt.Errorf("error unmarshalling config %s: %v", path, err)
+	}
+	for _, key := range legacyKeys(data) {
+		logs.Log.Warn(fmt.Sprintf("config %s: consensus.%s is ignored, set consensus.%s instead", path, key, legacyConsensusKeys[key]))
+	}
+	conf.fillDefaults()
+	conf.BaseConfig.RootDir = HomeDir()
+	return conf, nil
+}
+
+// legacyKeys returns the keys of legacyConsensusKeys set in the config file
+// data, sorted.
+func legacyKeys(data []byte) []string {
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return nil
+	}
+	var found []string
+	for key := range legacyConsensusKeys {
+		if tree.Has("consensus." + key) {
+			found = append(found, key)
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// loadLayered reads the config file at path and applies the TRACKS_*
+// environment variables and then the flags given to tracks start over it.
+// The source of every effective value is logged.
+func loadLayered(path string) (*Config, error) {
+	conf, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error in reading config file: %s : %v", path, err)
+	}
+
+	fields := conf.Fields()
+	if err = markFileFields(fields, data); err != nil {
+		return nil, fmt.Errorf("error parsing config %s: %v", path, err)
+	}
+	if err = applyEnv(fields); err != nil {
+		return nil, err
+	}
+	if err = applyFlags(fields); err != nil {
+		return nil, err
+	}
+
+	// overrides are logged one by one, the rest only by source
+	var fromFile, fromDefault []string
+	for _, f := range fields {
+		switch f.Source {
+		case SourceEnv, SourceFlag:
+			logs.Log.Info(fmt.Sprintf("config %s = %s (from %s %s)", f.Key, f, f.Source, f.Origin))
+		case SourceFile:
+			fromFile = append(fromFile, f.Key)
+		default:
+			fromDefault = append(fromDefault, f.Key)
+		}
+	}
+	logs.Log.Debug(fmt.Sprintf("config from %s: %s", path, strings.Join(fromFile, ", ")))
+	if len(fromDefault) > 0 {
+		logs.Log.Debug("config left at defaults: " + strings.Join(fromDefault, ", "))
+	}
+	return conf, nil
+}
+
+// Current returns the config of HomeDir. The file is read on the first call
+// and the same *Config is returned afterwards. Values from the environment
+// and from the flags of tracks start take precedence over the file.
+func Current() (*Config, error) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if current != nil {
+		return current, nil
+	}
+
+	configDir := ConfigPath()
+	if _, err := os.Stat(configDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("config directory not found: %s", configDir)
+	}
+	conf, err := loadLayered(ConfigPath(DefaultConfigFileName))
+	if err != nil {
+		return nil, err
+	}
+	current = conf
+	return current, nil
+}
+
+// SetCurrent replaces the config returned by Current, after the file has
+// been rewritten.
+func SetCurrent(conf *Config) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = conf
+}
+
+func (cfg *Config) fillDefaults() {
+	defaults := DefaultConfig()
+	if cfg.BaseConfig == nil {
+		cfg.BaseConfig = defaults.BaseConfig
+	}
+	if cfg.RPC == nil {
+		cfg.RPC = defaults.RPC
+	}
+	if cfg.P2P == nil {
+		cfg.P2P = defaults.P2P
+	}
+	if cfg.StateSync == nil {
+		cfg.StateSync = defaults.StateSync
+	}
+	if cfg.Consensus == nil {
+		cfg.Consensus = defaults.Consensus
+	}
+	if cfg.DA == nil {
+		cfg.DA = defaults.DA
+	}
+	if cfg.Station == nil {
+		cfg.Station = defaults.Station
+	}
+	if cfg.Junction == nil {
+		cfg.Junction = defaults.Junction
+	}
+}
@@ -0,0 +1,275 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pelletier/go-toml"
+	"github.com/spf13/pflag"
+)
+
+// EnvPrefix starts the name of every environment variable overriding a
+// sequencer.toml field, e.g. TRACKS_RPC_LISTEN_ADDRESS.
+const EnvPrefix = "TRACKS_"
+
+// Sources of an effective config value, from the lowest to the highest
+// precedence.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Field is a sequencer.toml field that can be overridden at runtime.
+type Field struct {
+	Key    string // section.key, as written in sequencer.toml
+	Env    string // TRACKS_SECTION_KEY
+	Flag   string // section-key, a flag of tracks start
+	Secret bool   // masked when the value is logged
+
+	// Source tells where the value of the field came from.
+	Source string
+	// Origin is the env variable or flag the value came from.
+	Origin string
+
+	value reflect.Value
+}
+
+// secretFields are never logged in clear.
+var secretFields = map[string]bool{
+	"da.daKey":               true,
+	"junction.VRFPrivateKey": true,
+}
+
+// Fields lists the overridable fields of cfg. Slices are written as comma
+// separated lists and durations in time.ParseDuration format.
+func (cfg *Config) Fields() []*Field {
+	var fields []*Field
+	sections := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		sectionField := sections.Type().Field(i)
+		section := sections.Field(i)
+		if section.Kind() != reflect.Ptr || section.IsNil() {
+			continue
+		}
+		sectionName := tomlName(sectionField)
+		sectionWords := words(sectionName)
+
+		section = section.Elem()
+		for j := 0; j < section.NumField(); j++ {
+			field := section.Type().Field(j)
+			// RootDir is always the home directory, see Load
+			if field.PkgPath != "" || field.Name == "RootDir" || !settable(field.Type) {
+				continue
+			}
+			name := tomlName(field)
+			fieldWords := words(name)
+			// DaRPC in [da] is TRACKS_DA_RPC, not TRACKS_DA_DA_RPC
+			if len(fieldWords) > 1 && strings.EqualFold(strings.Join(sectionWords, ""), fieldWords[0]) {
+				fieldWords = fieldWords[1:]
+			}
+			all := append(append([]string{}, sectionWords...), fieldWords...)
+
+			key := sectionName + "." + name
+			fields = append(fields, &Field{
+				Key:    key,
+				Env:    EnvPrefix + strings.ToUpper(strings.Join(all, "_")),
+				Flag:   strings.ToLower(strings.Join(all, "-")),
+				Secret: secretFields[key],
+				Source: SourceDefault,
+				value:  section.Field(j),
+			})
+		}
+	}
+	return fields
+}
+
+// String returns the value of the field as it is set from the environment,
+// with secrets masked.
+func (f *Field) String() string {
+	if f.Secret && !f.value.IsZero() {
+		return "****"
+	}
+	if f.value.Kind() == reflect.Slice {
+		return strings.Join(f.value.Interface().([]string), ",")
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// Set parses value into the field.
+func (f *Field) Set(value string) error {
+	v := f.value
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int, v.Kind() == reflect.Int32, v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case v.Kind() == reflect.Slice:
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	}
+	return nil
+}
+
+func settable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// tomlName is the key go-toml reads a field from, written the way the
+// template writes it.
+func tomlName(field reflect.StructField) string {
+	if tag := strings.Split(field.Tag.Get("toml"), ",")[0]; tag != "" {
+		return tag
+	}
+	runes := []rune(field.Name)
+	// DaRPC is written daRPC, VRFPrivateKey stays as is
+	if len(runes) > 1 && unicode.IsLower(runes[1]) {
+		runes[0] = unicode.ToLower(runes[0])
+	}
+	return string(runes)
+}
+
+// words splits snake_case and CamelCase names: "listen_address" is
+// [listen address] and "VRFPrivateKey" is [VRF Private Key].
+func words(name string) []string {
+	var out []string
+	for _, part := range strings.Split(name, "_") {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			upper := unicode.IsUpper(runes[i])
+			boundary := upper && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])))
+			if boundary {
+				out = append(out, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			out = append(out, string(runes[start:]))
+		}
+	}
+	return out
+}
+
+// normalizeKey matches keys the way go-toml does, ignoring case; underscores
+// are dropped so stationRPC and station_rpc compare equal.
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", ""))
+}
+
+// markFileFields sets the source of every field present in the config file.
+func markFileFields(fields []*Field, data []byte) error {
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return err
+	}
+	inFile := map[string]bool{}
+	for _, section := range tree.Keys() {
+		sub, ok := tree.Get(section).(*toml.Tree)
+		if !ok {
+			continue
+		}
+		for _, key := range sub.Keys() {
+			inFile[normalizeKey(section)+"."+normalizeKey(key)] = true
+		}
+	}
+	for _, f := range fields {
+		parts := strings.SplitN(f.Key, ".", 2)
+		if inFile[normalizeKey(parts[0])+"."+normalizeKey(parts[1])] {
+			f.Source = SourceFile
+		}
+	}
+	return nil
+}
+
+// applyEnv overrides fields from TRACKS_* environment variables.
+func applyEnv(fields []*Field) error {
+	for _, f := range fields {
+		value, ok := os.LookupEnv(f.Env)
+		if !ok {
+			continue
+		}
+		if err := f.Set(value); err != nil {
+			return fmt.Errorf("invalid value for %s: %v", f.Env, err)
+		}
+		f.Source, f.Origin = SourceEnv, f.Env
+	}
+	return nil
+}
+
+var flagOverrides = map[string]string{}
+
+// RegisterFlags adds a flag overriding each sequencer.toml field to fs.
+func RegisterFlags(fs *pflag.FlagSet) {
+	for _, f := range DefaultConfig().Fields() {
+		fs.String(f.Flag, "", fmt.Sprintf("Override %s (env %s)", f.Key, f.Env))
+	}
+}
+
+// SetFlagOverrides records the flags of RegisterFlags given on the command
+// line. They are applied over the file and the environment by Current.
+func SetFlagOverrides(fs *pflag.FlagSet) {
+	known := map[string]bool{}
+	for _, f := range DefaultConfig().Fields() {
+		known[f.Flag] = true
+	}
+	fs.Visit(func(flag *pflag.Flag) {
+		if known[flag.Name] {
+			flagOverrides[flag.Name] = flag.Value.String()
+		}
+	})
+}
+
+func applyFlags(fields []*Field) error {
+	for _, f := range fields {
+		value, ok := flagOverrides[f.Flag]
+		if !ok {
+			continue
+		}
+		if err := f.Set(value); err != nil {
+			return fmt.Errorf("invalid value for --%s: %v", f.Flag, err)
+		}
+		f.Source, f.Origin = SourceFlag, "--"+f.Flag
+	}
+	return nil
+}
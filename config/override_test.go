@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFieldNames(t *testing.T) {
+	want := map[string][2]string{
+		"rpc.listen_address":    {"TRACKS_RPC_LISTEN_ADDRESS", "rpc-listen-address"},
+		"p2p.persistent_peers":  {"TRACKS_P2P_PERSISTENT_PEERS", "p2p-persistent-peers"},
+		"da.daRPC":              {"TRACKS_DA_RPC", "da-rpc"},
+		"station.stationRPC":    {"TRACKS_STATION_RPC", "station-rpc"},
+		"junction.VRFPublicKey": {"TRACKS_JUNCTION_VRF_PUBLIC_KEY", "junction-vrf-public-key"},
+	}
+	for _, f := range DefaultConfig().Fields() {
+		if names, ok := want[f.Key]; ok {
+			if f.Env != names[0] || f.Flag != names[1] {
+				t.Errorf("%s: got %s --%s, want %s --%s", f.Key, f.Env, f.Flag, names[0], names[1])
+			}
+			delete(want, f.Key)
+		}
+	}
+	for key := range want {
+		t.Errorf("%s is not overridable", key)
+	}
+}
+
+func TestLayeredPrecedence(t *testing.T) {
+	data := []byte(`
+[rpc]
+listen_address = "tcp://0.0.0.0:1000"
+
+[p2p]
+listen_address = "tcp://0.0.0.0:2000"
+
+[station]
+stationRPC = "http://127.0.0.1:8545"
+`)
+	path := filepath.Join(t.TempDir(), DefaultConfigFileName)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TRACKS_RPC_LISTEN_ADDRESS", "tcp://0.0.0.0:1001")
+	t.Setenv("TRACKS_P2P_LISTEN_ADDRESS", "tcp://0.0.0.0:2001")
+	t.Setenv("TRACKS_P2P_PERSISTENT_PEERS", "a, b")
+	t.Setenv("TRACKS_STATION_POLL_INTERVAL", "5s")
+	flagOverrides = map[string]string{"p2p-listen-address": "tcp://0.0.0.0:2002"}
+	defer func() { flagOverrides = map[string]string{} }()
+
+	conf, err := loadLayered(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.RPC.ListenAddress != "tcp://0.0.0.0:1001" {
+		t.Errorf("rpc listen address %q, want the env value", conf.RPC.ListenAddress)
+	}
+	if conf.P2P.ListenAddress != "tcp://0.0.0.0:2002" {
+		t.Errorf("p2p listen address %q, want the flag value", conf.P2P.ListenAddress)
+	}
+	if len(conf.P2P.PersistentPeers) != 2 || conf.P2P.PersistentPeers[1] != "b" {
+		t.Errorf("persistent peers %q, want [a b]", conf.P2P.PersistentPeers)
+	}
+	if conf.Station.PollInterval.String() != "5s" {
+		t.Errorf("poll interval %s, want 5s", conf.Station.PollInterval)
+	}
+	if conf.Station.StationRPC != "http://127.0.0.1:8545" {
+		t.Errorf("station rpc %q, want the file value", conf.Station.StationRPC)
+	}
+}
+
+func TestLoadIgnoresLegacyTimeouts(t *testing.T) {
+	data := []byte(`
+[consensus]
+timeout_propose = "3s"
+timeout_commit = "1s"
+pod_prevote_timeout = "2m"
+`)
+	path := filepath.Join(t.TempDir(), DefaultConfigFileName)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := DefaultConsensusConfig()
+	if conf.Consensus.TimeoutPropose != defaults.TimeoutPropose || conf.Consensus.TimeoutCommit != defaults.TimeoutCommit {
+		t.Errorf("timeouts %s and %s, want the defaults", conf.Consensus.TimeoutPropose, conf.Consensus.TimeoutCommit)
+	}
+	if conf.Consensus.TimeoutPrevote != 2*time.Minute {
+		t.Errorf("prevote timeout %s, want 2m", conf.Consensus.TimeoutPrevote)
+	}
+	if got := legacyKeys(data); len(got) != 2 || got[0] != "timeout_commit" || got[1] != "timeout_propose" {
+		t.Errorf("legacy keys %v", got)
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	multiaddr "github.com/multiformats/go-multiaddr"
+)
+
+// ListenMultiaddrs returns the addresses the libp2p host listens on.
+// ListenAddress is either tcp://host:port or a comma separated list of
+// multiaddrs, e.g. "/ip4/0.0.0.0/tcp/2300,/ip4/0.0.0.0/udp/2300/quic-v1".
+func (cfg *P2PConfig) ListenMultiaddrs() ([]multiaddr.Multiaddr, error) {
+	return parseMultiaddrs(cfg.ListenAddress)
+}
+
+// ExternalMultiaddrs returns the addresses announced to other tracks instead
+// of the listen addresses, or nil when ExternalAddress is not set.
+func (cfg *P2PConfig) ExternalMultiaddrs() ([]multiaddr.Multiaddr, error) {
+	if strings.TrimSpace(cfg.ExternalAddress) == "" {
+		return nil, nil
+	}
+	return parseMultiaddrs(cfg.ExternalAddress)
+}
+
+func parseMultiaddrs(value string) ([]multiaddr.Multiaddr, error) {
+	var addrs []multiaddr.Multiaddr
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		addr, err := toMultiaddr(item)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no address in %q", value)
+	}
+	return addrs, nil
+}
+
+// toMultiaddr parses a multiaddr, or converts tcp://host:port into one.
+func toMultiaddr(address string) (multiaddr.Multiaddr, error) {
+	if strings.HasPrefix(address, "/") {
+		return multiaddr.NewMultiaddr(address)
+	}
+
+	u, err := url.Parse(address)
+	if err != nil || u.Scheme != "tcp" {
+		return nil, fmt.Errorf("%q is neither a multiaddr nor tcp://host:port", address)
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither a multiaddr nor tcp://host:port: %v", address, err)
+	}
+	protocol := "dns"
+	if ip := net.ParseIP(host); ip != nil {
+		protocol = "ip6"
+		if ip.To4() != nil {
+			protocol = "ip4"
+		}
+	}
+	return multiaddr.NewMultiaddr(fmt.Sprintf("/%s/%s/tcp/%s", protocol, host, port))
+}
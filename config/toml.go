@@ -74,7 +74,7 @@ func WriteConfigFile(configFilePath string, config *Config) {
 */
 
 const defaultConfigTemplate = `[base_config]
-db_backend="goleveldb"
+db_backend="{{ .BaseConfig.DBBackend }}"
 db_path="{{ .BaseConfig.DBPath }}"
 filter_peers={{ .BaseConfig.FilterPeers }}
 moniker="{{ .BaseConfig.Moniker }}"
@@ -84,21 +84,23 @@ version="0.0.1"
 
 [consensus]
 double_sign_check_height = {{ .Consensus.DoubleSignCheckHeight }}
+pod_commit_timeout = "{{ .Consensus.TimeoutCommit }}"
+pod_precommit_timeout = "{{ .Consensus.TimeoutPrecommit }}"
+pod_precommit_timeout_delta = "{{ .Consensus.TimeoutPrecommitDelta }}"
+pod_prevote_timeout = "{{ .Consensus.TimeoutPrevote }}"
+pod_prevote_timeout_delta = "{{ .Consensus.TimeoutPrevoteDelta }}"
+pod_propose_timeout = "{{ .Consensus.TimeoutPropose }}"
+pod_propose_timeout_delta = "{{ .Consensus.TimeoutProposeDelta }}"
 pod_validation_sleep_duration = "{{ .Consensus.PodValidationSleepDuration }}"
+quorum_percent = {{ .Consensus.QuorumPercent }}
 skip_timeout_commit = {{ .Consensus.SkipTimeoutCommit }}
-timeout_commit = "{{ .Consensus.TimeoutCommit }}"
-timeout_precommit = "{{ .Consensus.TimeoutPrecommit }}"
-timeout_precommit_delta = "{{ .Consensus.TimeoutPrecommitDelta }}"
-timeout_prevote = "{{ .Consensus.TimeoutPrevote }}"
-timeout_prevote_delta = "{{ .Consensus.TimeoutPrevoteDelta }}"
-timeout_propose = "{{ .Consensus.TimeoutPropose }}"
-timeout_propose_delta = "{{ .Consensus.TimeoutProposeDelta }}"
 validatePods = {{ .Consensus.ValidatePods }}
 
 [da]
 daKey = "{{ .DA.DaKey }}"
 daRPC = "{{ .DA.DaRPC }}"
 daType = "{{ .DA.DaType }}"
+daCompression = {{ .DA.DaCompression }}
 
 [junction]
 accountName = "{{ .Junction.AccountName }}"
@@ -112,9 +114,15 @@ VRFPrivateKey = "{{ .Junction.VRFPrivateKey }}"
 VRFPublicKey = "{{ .Junction.VRFPublicKey }}"
 
 [p2p]
+ban_duration = "{{ .P2P.BanDuration }}"
+ban_threshold = {{ .P2P.BanThreshold }}
+bootstrap_peers = [{{ range .P2P.BootstrapPeers }} "{{ . }}", {{ end }}]
 currently_connected_peers = {{ .P2P.CurrentlyConnectedPeers }}
+dht = {{ .P2P.DHT }}
 external_address = "{{ .P2P.ExternalAddress }}"
 listen_address = "{{ .P2P.ListenAddress }}"
+mdns = {{ .P2P.MDNS }}
+message_rate_limit = {{ .P2P.MessageRateLimit }}
 node_id = "{{ .P2P.NodeId }}"
 persistent_peers = {{ .P2P.PersistentPeers }}
 root_dir = "{{ .P2P.RootDir }}"
@@ -157,5 +165,9 @@ temp_dir = "{{ .StateSync.TempDir }}"
 stationAPI = "{{ .Station.StationAPI }}"
 stationRPC = "{{ .Station.StationRPC }}"
 stationType = "{{ .Station.StationType }}"
+stationWS = "{{ .Station.StationWS }}"
+pollInterval = "{{ .Station.PollInterval }}"
+confirmationDepth = {{ .Station.ConfirmationDepth }}
+backfillWorkers = {{ .Station.BackfillWorkers }}
 
 `
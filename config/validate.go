@@ -0,0 +1,271 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// StationTypes are the station types the indexer supports.
+var StationTypes = []string{"evm", "wasm", "svm"}
+
+// DBBackends are the storage engines of the store package.
+var DBBackends = []string{"goleveldb", "bbolt"}
+
+// ValidationError lists every problem found by Validate.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(field string, format string, args ...interface{}) {
+	v.problems = append(v.problems, field+": "+fmt.Sprintf(format, args...))
+}
+
+// Validate checks the config for values the node can not run with and
+// returns a *ValidationError listing all of them.
+func (cfg *Config) Validate() error {
+	v := &validator{}
+	v.base(cfg.BaseConfig)
+	v.rpc(cfg.RPC)
+	v.p2p(cfg.P2P)
+	v.consensus(cfg.Consensus)
+	v.da(cfg.DA)
+	v.station(cfg.Station)
+	v.junction(cfg.Junction)
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+func (v *validator) base(c *BaseConfig) {
+	if c == nil {
+		v.addf("base_config", "section is missing")
+		return
+	}
+	// an empty backend is goleveldb
+	if c.DBBackend != "" && !oneOf(c.DBBackend, DBBackends) {
+		v.addf("base_config.db_backend", "%q is not one of %s", c.DBBackend, strings.Join(DBBackends, ", "))
+	}
+}
+
+func (v *validator) rpc(c *RPCConfig) {
+	if c == nil {
+		v.addf("rpc", "section is missing")
+		return
+	}
+	v.listenAddress("rpc.listen_address", c.ListenAddress)
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		v.addf("rpc", "tls_cert_file and tls_key_file must be set together")
+	}
+	if c.MaxBodyBytes < 0 {
+		v.addf("rpc.max_body_bytes", "must not be negative")
+	}
+	if c.MaxHeaderBytes < 0 {
+		v.addf("rpc.max_header_bytes", "must not be negative")
+	}
+	if c.MaxOpenConnections < 0 {
+		v.addf("rpc.max_open_connections", "must not be negative")
+	}
+}
+
+func (v *validator) p2p(c *P2PConfig) {
+	if c == nil {
+		v.addf("p2p", "section is missing")
+		return
+	}
+	if _, err := c.ListenMultiaddrs(); err != nil {
+		v.addf("p2p.listen_address", "%v", err)
+	}
+	if _, err := c.ExternalMultiaddrs(); err != nil {
+		v.addf("p2p.external_address", "%v", err)
+	}
+	if c.NodeId != "" {
+		if err := c.NodeId.Validate(); err != nil {
+			v.addf("p2p.node_id", "%q is not a peer ID: %v", c.NodeId, err)
+		}
+	}
+	for _, p := range c.PersistentPeers {
+		if _, err := peer.AddrInfoFromString(p); err != nil {
+			v.addf("p2p.persistent_peers", "%q is not a multiaddr ending in /p2p/<peer ID>: %v", p, err)
+		}
+	}
+	for _, p := range c.BootstrapPeers {
+		if _, err := peer.AddrInfoFromString(p); err != nil {
+			v.addf("p2p.bootstrap_peers", "%q is not a multiaddr ending in /p2p/<peer ID>: %v", p, err)
+		}
+	}
+	if c.BanThreshold >= 0 {
+		v.addf("p2p.ban_threshold", "must be negative, peers start at 0")
+	}
+	if c.BanDuration <= 0 {
+		v.addf("p2p.ban_duration", "must be positive")
+	}
+	if c.MessageRateLimit < 0 {
+		v.addf("p2p.message_rate_limit", "must not be negative")
+	}
+}
+
+func (v *validator) consensus(c *ConsensusConfig) {
+	if c == nil {
+		v.addf("consensus", "section is missing")
+		return
+	}
+	for _, t := range []struct {
+		key   string
+		value time.Duration
+		delta bool
+	}{
+		{"pod_propose_timeout", c.TimeoutPropose, false},
+		{"pod_propose_timeout_delta", c.TimeoutProposeDelta, true},
+		{"pod_prevote_timeout", c.TimeoutPrevote, false},
+		{"pod_prevote_timeout_delta", c.TimeoutPrevoteDelta, true},
+		{"pod_precommit_timeout", c.TimeoutPrecommit, false},
+		{"pod_precommit_timeout_delta", c.TimeoutPrecommitDelta, true},
+		{"pod_commit_timeout", c.TimeoutCommit, false},
+		{"pod_validation_sleep_duration", c.PodValidationSleepDuration, false},
+	} {
+		if t.delta && t.value < 0 {
+			v.addf("consensus."+t.key, "must not be negative")
+		}
+		if !t.delta && t.value <= 0 {
+			v.addf("consensus."+t.key, "must be positive")
+		}
+	}
+	if c.QuorumPercent < 1 || c.QuorumPercent > 100 {
+		v.addf("consensus.quorum_percent", "%d is not between 1 and 100", c.QuorumPercent)
+	}
+}
+
+func (v *validator) da(c *DAConfig) {
+	if c == nil {
+		v.addf("da", "section is missing")
+		return
+	}
+	check, ok := daCheck(c.DaType)
+	if !ok {
+		v.addf("da.daType", "%q is not one of %s", c.DaType, strings.Join(DATypes(), ", "))
+		return
+	}
+	if check != nil {
+		check(c, DAProblems{v: v})
+	}
+}
+
+func (v *validator) station(c *StationConfig) {
+	if c == nil {
+		v.addf("station", "section is missing")
+		return
+	}
+	if !oneOf(strings.ToLower(c.StationType), StationTypes) {
+		v.addf("station.stationType", "%q is not one of %s", c.StationType, strings.Join(StationTypes, ", "))
+	}
+	v.url("station.stationRPC", c.StationRPC, true, "http", "https")
+	v.url("station.stationAPI", c.StationAPI, true, "http", "https")
+	v.url("station.stationWS", c.StationWS, false, "ws", "wss")
+	if c.PollInterval < 0 {
+		v.addf("station.pollInterval", "must not be negative")
+	}
+	if c.BackfillWorkers < 0 {
+		v.addf("station.backfillWorkers", "must not be negative")
+	}
+}
+
+func (v *validator) junction(c *JunctionConfig) {
+	if c == nil {
+		v.addf("junction", "section is missing")
+		return
+	}
+	v.url("junction.junctionRPC", c.JunctionRPC, false, "http", "https")
+	v.url("junction.junctionAPI", c.JunctionAPI, false, "http", "https")
+	v.hexKey("junction.VRFPrivateKey", c.VRFPrivateKey)
+	v.hexKey("junction.VRFPublicKey", c.VRFPublicKey)
+	if (c.VRFPrivateKey == "") != (c.VRFPublicKey == "") {
+		v.addf("junction", "VRFPrivateKey and VRFPublicKey must be set together")
+	}
+	for _, track := range c.Tracks {
+		if c.AddressPrefix != "" && !strings.HasPrefix(track, c.AddressPrefix) {
+			v.addf("junction.Tracks", "%q does not start with the address prefix %q", track, c.AddressPrefix)
+		}
+	}
+
+	// fields written by create-station
+	if c.StationId == "" {
+		return
+	}
+	if c.JunctionRPC == "" {
+		v.addf("junction.junctionRPC", "required once the station is created")
+	}
+	if c.AccountName == "" {
+		v.addf("junction.accountName", "required once the station is created")
+	}
+	if c.AccountPath == "" {
+		v.addf("junction.accountPath", "required once the station is created")
+	}
+	if c.VRFPrivateKey == "" {
+		v.addf("junction", "VRF keys are required once the station is created")
+	}
+	if len(c.Tracks) == 0 {
+		v.addf("junction.Tracks", "at least one track is required once the station is created")
+	}
+}
+
+// listenAddress checks a tcp://host:port address.
+func (v *validator) listenAddress(field string, address string) {
+	u, err := url.Parse(address)
+	if err != nil || u.Scheme != "tcp" {
+		v.addf(field, "%q must look like tcp://host:port", address)
+		return
+	}
+	if _, _, err = net.SplitHostPort(u.Host); err != nil {
+		v.addf(field, "%q must look like tcp://host:port: %v", address, err)
+	}
+}
+
+func (v *validator) url(field string, value string, required bool, schemes ...string) {
+	if value == "" {
+		if required {
+			v.addf(field, "is required")
+		}
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		v.addf(field, "%q is not a URL: %v", value, err)
+		return
+	}
+	if !oneOf(u.Scheme, schemes) || u.Host == "" {
+		v.addf(field, "%q must be a %s URL", value, strings.Join(schemes, " or "))
+	}
+}
+
+func (v *validator) hexKey(field string, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := hex.DecodeString(value); err != nil {
+		v.addf(field, "is not hex encoded: %v", err)
+	}
+}
+
+func oneOf(value string, values []string) bool {
+	for _, candidate := range values {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func init() {
+	// the da package registers the real backends; config can not import it
+	RegisterDAType("mock", nil)
+	RegisterDAType("inhouse", func(c *DAConfig, problems DAProblems) {
+		problems.URL("da.daRPC", c.DaRPC, true, "http", "https")
+		if c.DaKey == "" {
+			problems.Addf("da.daKey", "is required")
+		}
+	})
+}
+
+func validConfig() *Config {
+	conf := DefaultConfig()
+	conf.DA.DaType = "mock"
+	conf.Station.StationType = "evm"
+	conf.Station.StationRPC = "http://127.0.0.1:8545"
+	conf.Station.StationAPI = "http://127.0.0.1:8545"
+	return conf
+}
+
+func TestValidateAcceptsInitConfig(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateListsEveryProblem(t *testing.T) {
+	conf := validConfig()
+	conf.Station.StationType = "cosmwasm"
+	conf.Station.StationWS = "http://127.0.0.1:8546"
+	conf.DA.DaType = "inhouse"
+	conf.P2P.PersistentPeers = []string{"/ip4/127.0.0.1/tcp/2300"}
+	conf.Consensus.TimeoutPropose = 0
+
+	err := conf.Validate()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Validate returned %v, want a *ValidationError", err)
+	}
+	for _, field := range []string{"station.stationType", "station.stationWS", "da.daRPC", "da.daKey", "p2p.persistent_peers", "consensus.pod_propose_timeout"} {
+		if !strings.Contains(err.Error(), field+":") {
+			t.Errorf("%s missing from %q", field, err.Error())
+		}
+	}
+}
+
+func TestValidateFollowsRegisteredDATypes(t *testing.T) {
+	conf := validConfig()
+	conf.DA.DaType = "inhouse"
+	conf.DA.DaRPC = "http://127.0.0.1:7000"
+	conf.DA.DaKey = "key"
+	if err := conf.Validate(); err != nil {
+		t.Fatalf("registered DA type refused: %v", err)
+	}
+	conf.DA.DaType = "unregistered"
+	if err := conf.Validate(); err == nil || !strings.Contains(err.Error(), "da.daType:") {
+		t.Fatalf("unregistered DA type: %v", err)
+	}
+}
+
+func TestListenMultiaddrs(t *testing.T) {
+	for address, want := range map[string]string{
+		"tcp://0.0.0.0:2300":   "/ip4/0.0.0.0/tcp/2300",
+		"tcp://[::]:2300":      "/ip6/::/tcp/2300",
+		"tcp://tracks.io:2300": "/dns/tracks.io/tcp/2300",
+		"/ip4/0.0.0.0/tcp/2300, /ip4/0.0.0.0/udp/2300/quic-v1": "/ip4/0.0.0.0/tcp/2300 /ip4/0.0.0.0/udp/2300/quic-v1",
+	} {
+		addrs, err := (&P2PConfig{ListenAddress: address}).ListenMultiaddrs()
+		if err != nil {
+			t.Errorf("%s: %v", address, err)
+			continue
+		}
+		var got []string
+		for _, addr := range addrs {
+			got = append(got, addr.String())
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("%s: got %v, want %s", address, got, want)
+		}
+	}
+
+	if _, err := (&P2PConfig{ListenAddress: "0.0.0.0:2300"}).ListenMultiaddrs(); err == nil {
+		t.Error("0.0.0.0:2300 was accepted")
+	}
+}
@@ -3,13 +3,106 @@ package avail
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/airchains-network/decentralized-sequencer/da"
 	"github.com/airchains-network/decentralized-sequencer/types"
+	"golang.org/x/crypto/blake2b"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
+// ClientName is stored as DAClientName for pods posted to Avail.
+const ClientName = "avail-da"
+
+func init() {
+	da.RegisterWithCheck("avail", func(cfg *config.DAConfig) (da.Client, error) {
+		if cfg.DaRPC == "" {
+			return nil, fmt.Errorf("daRPC is required for avail")
+		}
+		return &Client{daRpc: cfg.DaRPC}, nil
+	}, func(c *config.DAConfig, problems config.DAProblems) {
+		problems.URL("da.daRPC", c.DaRPC, true, "http", "https")
+	})
+}
+
+// Client is the da.Client for the Avail light client HTTP API.
+type Client struct {
+	daRpc string
+}
+
+func (c *Client) Name() string {
+	return ClientName
+}
+
+func (c *Client) Submit(daData []byte, _ int) (string, error) {
+	return Avail(daData, c.daRpc)
+}
+
+// Retrieve reads the block named in daKey from the light client and returns the
+// data of the extrinsic whose hash matches the one recorded at submission.
+func (c *Client) Retrieve(daKey string) ([]byte, error) {
+	blockNumber, txHash, err := parseDAKey(daKey)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := http.Get(fmt.Sprintf("%s/v2/blocks/%d/data?fields=data,extrinsic", c.daRpc, blockNumber))
+	if err != nil {
+		return nil, fmt.Errorf("API call failed: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != 200 {
+		return nil, fmt.Errorf("block data not available, Status : %v, Call : %s", response.StatusCode, response.Status)
+	}
+
+	var blockData types.AvailBlockData
+	if err = json.NewDecoder(response.Body).Decode(&blockData); err != nil {
+		return nil, fmt.Errorf("error parsing block data: %v", err)
+	}
+
+	for _, dataTx := range blockData.DataTransactions {
+		extrinsic, err := base64.StdEncoding.DecodeString(dataTx.Extrinsic)
+		if err != nil {
+			continue
+		}
+		hash := blake2b.Sum256(extrinsic)
+		if !strings.EqualFold("0x"+hex.EncodeToString(hash[:]), txHash) {
+			continue
+		}
+		return base64.StdEncoding.DecodeString(dataTx.Data)
+	}
+
+	return nil, fmt.Errorf("transaction %s not found in block %d", txHash, blockNumber)
+}
+
+// Status reports "included" once the submitted extrinsic is found in its block.
+func (c *Client) Status(daKey string) (string, error) {
+	if _, err := c.Retrieve(daKey); err != nil {
+		return "", err
+	}
+	return "included", nil
+}
+
+func parseDAKey(daKey string) (int, string, error) {
+	blockNumberStr, txHash, found := strings.Cut(daKey, ":")
+	if !found || txHash == "" {
+		return 0, "", fmt.Errorf("avail DA key %q has no block number, it can not be retrieved", daKey)
+	}
+	blockNumber, err := strconv.Atoi(blockNumberStr)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid block number in avail DA key %q: %v", daKey, err)
+	}
+	return blockNumber, txHash, nil
+}
+
+// Avail submits daData through the light client and returns a DA key of the
+// form "<block number>:<transaction hash>".
 func Avail(daData []byte, daRpc string) (string, error) {
 
 	encodedString := base64.StdEncoding.EncodeToString(daData)
@@ -51,6 +144,6 @@ func Avail(daData []byte, daRpc string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("error parsing response: %s", response.Body)
 	}
-	return successResponse.BlockHash, nil
+	return fmt.Sprintf("%d:%s", successResponse.BlockNumber, successResponse.Hash), nil
 
 }
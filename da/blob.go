@@ -0,0 +1,233 @@
+package da
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/airchains-network/decentralized-sequencer/types"
+)
+
+// Pod blobs posted to the DA layer start with a fixed header:
+//
+//	magic (4 bytes "TRKP") | version (1 byte) | flags (1 byte) | body
+//
+// The body holds the fields of PodBlob in order. Integers are uvarints, byte
+// slices and strings are prefixed with their uvarint length and string lists
+// with their uvarint element count. When BlobFlagGzip is set the body is gzip
+// compressed.
+const (
+	BlobVersion  byte = 1
+	BlobFlagGzip byte = 1 << 0
+
+	blobHeaderSize = 6
+
+	// maxBlobFieldSize bounds a single length prefix so a corrupt blob can not
+	// make the decoder allocate unbounded memory.
+	maxBlobFieldSize = 64 << 20
+)
+
+var blobMagic = []byte("TRKP")
+
+// ErrInvalidBlob is returned by DecodePodBlob for data that is not a pod blob.
+var ErrInvalidBlob = errors.New("invalid pod blob")
+
+// PodBlob is everything posted to the DA layer for a pod, enough to re-derive
+// the pod and its hash without access to the station.
+type PodBlob struct {
+	PodNumber       uint64
+	PreviousPodHash []byte
+	PodHash         []byte
+	TracksAppHash   []byte
+	Proof           []byte
+	PublicWitness   []byte
+	Batch           *types.BatchStruct
+}
+
+// EncodePodBlob serialises blob into the versioned DA blob format, gzip
+// compressing the body when compress is set.
+func EncodePodBlob(blob *PodBlob, compress bool) ([]byte, error) {
+	var body bytes.Buffer
+	putUvarint(&body, blob.PodNumber)
+	putBytes(&body, blob.PreviousPodHash)
+	putBytes(&body, blob.PodHash)
+	putBytes(&body, blob.TracksAppHash)
+	putBytes(&body, blob.Proof)
+	putBytes(&body, blob.PublicWitness)
+
+	batch := blob.Batch
+	if batch == nil {
+		batch = &types.BatchStruct{}
+	}
+	for _, list := range batchLists(batch) {
+		putStrings(&body, *list)
+	}
+
+	var flags byte
+	payload := body.Bytes()
+	if compress {
+		var compressed bytes.Buffer
+		zw := gzip.NewWriter(&compressed)
+		if _, err := zw.Write(payload); err != nil {
+			return nil, fmt.Errorf("error compressing pod blob: %v", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("error compressing pod blob: %v", err)
+		}
+		flags |= BlobFlagGzip
+		payload = compressed.Bytes()
+	}
+
+	out := make([]byte, 0, blobHeaderSize+len(payload))
+	out = append(out, blobMagic...)
+	out = append(out, BlobVersion, flags)
+	return append(out, payload...), nil
+}
+
+// DecodePodBlob parses a blob produced by EncodePodBlob.
+func DecodePodBlob(data []byte) (*PodBlob, error) {
+	if len(data) < blobHeaderSize || !bytes.Equal(data[:len(blobMagic)], blobMagic) {
+		return nil, ErrInvalidBlob
+	}
+	version, flags := data[4], data[5]
+	if version != BlobVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidBlob, version)
+	}
+	if flags&^BlobFlagGzip != 0 {
+		return nil, fmt.Errorf("%w: unknown flags %#x", ErrInvalidBlob, flags)
+	}
+
+	payload := data[blobHeaderSize:]
+	if flags&BlobFlagGzip != 0 {
+		zr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBlob, err)
+		}
+		payload, err = io.ReadAll(io.LimitReader(zr, maxBlobFieldSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBlob, err)
+		}
+		if len(payload) > maxBlobFieldSize {
+			return nil, fmt.Errorf("%w: decompressed body too large", ErrInvalidBlob)
+		}
+	}
+
+	r := &blobReader{buf: payload}
+	blob := &PodBlob{Batch: &types.BatchStruct{}}
+	blob.PodNumber = r.uvarint()
+	blob.PreviousPodHash = r.bytes()
+	blob.PodHash = r.bytes()
+	blob.TracksAppHash = r.bytes()
+	blob.Proof = r.bytes()
+	blob.PublicWitness = r.bytes()
+	for _, list := range batchLists(blob.Batch) {
+		*list = r.strings()
+	}
+
+	if r.err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBlob, r.err)
+	}
+	if len(r.buf) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidBlob, len(r.buf))
+	}
+	return blob, nil
+}
+
+// batchLists returns the fields of batch in wire order.
+func batchLists(batch *types.BatchStruct) []*[]string {
+	return []*[]string{
+		&batch.From,
+		&batch.To,
+		&batch.Amounts,
+		&batch.TransactionHash,
+		&batch.SenderBalances,
+		&batch.ReceiverBalances,
+		&batch.Messages,
+		&batch.TransactionNonces,
+		&batch.AccountNonces,
+	}
+}
+
+func putUvarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+}
+
+func putBytes(buf *bytes.Buffer, b []byte) {
+	putUvarint(buf, uint64(len(b)))
+	buf.Write(b)
+}
+
+func putStrings(buf *bytes.Buffer, list []string) {
+	putUvarint(buf, uint64(len(list)))
+	for _, s := range list {
+		putUvarint(buf, uint64(len(s)))
+		buf.WriteString(s)
+	}
+}
+
+// blobReader reads the body of a pod blob, keeping the first error so the
+// fields can be read in sequence and checked once at the end.
+type blobReader struct {
+	buf []byte
+	err error
+}
+
+func (r *blobReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.err = errors.New("malformed length prefix")
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *blobReader) next() []byte {
+	size := r.uvarint()
+	if r.err != nil {
+		return nil
+	}
+	if size > maxBlobFieldSize || size > uint64(len(r.buf)) {
+		r.err = fmt.Errorf("field of %d bytes overruns blob", size)
+		return nil
+	}
+	field := r.buf[:size:size]
+	r.buf = r.buf[size:]
+	return field
+}
+
+func (r *blobReader) bytes() []byte {
+	field := r.next()
+	if len(field) == 0 {
+		return nil
+	}
+	return append([]byte(nil), field...)
+}
+
+func (r *blobReader) strings() []string {
+	count := r.uvarint()
+	if r.err != nil || count == 0 {
+		return nil
+	}
+	// every element takes at least one byte for its length prefix
+	if count > uint64(len(r.buf)) {
+		r.err = fmt.Errorf("list of %d elements overruns blob", count)
+		return nil
+	}
+	list := make([]string, 0, count)
+	for i := uint64(0); i < count; i++ {
+		field := r.next()
+		if r.err != nil {
+			return nil
+		}
+		list = append(list, string(field))
+	}
+	return list
+}
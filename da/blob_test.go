@@ -0,0 +1,65 @@
+package da_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/airchains-network/decentralized-sequencer/da"
+	"github.com/airchains-network/decentralized-sequencer/types"
+)
+
+func TestPodBlobRoundTrip(t *testing.T) {
+	blob := &da.PodBlob{
+		PodNumber:       7,
+		PreviousPodHash: []byte("previous"),
+		PodHash:         []byte("current"),
+		TracksAppHash:   []byte("app-hash"),
+		Proof:           []byte{0, 1, 2, 3},
+		PublicWitness:   []byte("witness"),
+		Batch: &types.BatchStruct{
+			From:              []string{"0xa", "0xb"},
+			To:                []string{"0xc", "0xd"},
+			Amounts:           []string{"1", "2"},
+			TransactionHash:   []string{"0x01", "0x02"},
+			SenderBalances:    []string{"10", "20"},
+			ReceiverBalances:  []string{"30", "40"},
+			Messages:          []string{"", "hello"},
+			TransactionNonces: []string{"0", "1"},
+			AccountNonces:     []string{"5", "6"},
+		},
+	}
+
+	for _, compress := range []bool{false, true} {
+		data, err := da.EncodePodBlob(blob, compress)
+		if err != nil {
+			t.Fatalf("compress=%v: encode: %v", compress, err)
+		}
+		got, err := da.DecodePodBlob(data)
+		if err != nil {
+			t.Fatalf("compress=%v: decode: %v", compress, err)
+		}
+		if !reflect.DeepEqual(got, blob) {
+			t.Fatalf("compress=%v: round trip mismatch\ngot  %+v\nwant %+v", compress, got, blob)
+		}
+	}
+}
+
+func TestDecodePodBlobRejectsInvalidData(t *testing.T) {
+	data, err := da.EncodePodBlob(&da.PodBlob{PodNumber: 1}, false)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	cases := map[string][]byte{
+		"legacy hashes": []byte("0x010x02"),
+		"bad version":   append([]byte("TRKP\x09\x00"), data[6:]...),
+		"truncated":     data[:len(data)-1],
+		"trailing":      append(append([]byte{}, data...), 0),
+	}
+	for name, input := range cases {
+		if _, err := da.DecodePodBlob(input); !errors.Is(err, da.ErrInvalidBlob) {
+			t.Errorf("%s: expected ErrInvalidBlob, got %v", name, err)
+		}
+	}
+}
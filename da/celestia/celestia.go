@@ -5,49 +5,160 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/airchains-network/decentralized-sequencer/da"
 	"github.com/airchains-network/decentralized-sequencer/types"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
+// ClientName is stored as DAClientName for pods posted to Celestia.
+const ClientName = "celestia-da"
+
+func init() {
+	da.RegisterWithCheck("celestia", func(cfg *config.DAConfig) (da.Client, error) {
+		if cfg.DaRPC == "" {
+			return nil, fmt.Errorf("daRPC is required for celestia")
+		}
+		return &Client{daRpc: cfg.DaRPC, rpcAuth: cfg.DaKey}, nil
+	}, func(c *config.DAConfig, problems config.DAProblems) {
+		problems.URL("da.daRPC", c.DaRPC, true, "http", "https")
+		if c.DaKey == "" {
+			problems.Addf("da.daKey", "the celestia auth token is required")
+		}
+	})
+}
+
+// Client is the da.Client for a Celestia node's JSON-RPC API.
+type Client struct {
+	daRpc   string
+	rpcAuth string
+}
+
+func (c *Client) Name() string {
+	return ClientName
+}
+
+func (c *Client) Submit(daData []byte, _ int) (string, error) {
+	return Celestia(daData, c.daRpc, c.rpcAuth)
+}
+
+// Retrieve fetches the blob with blob.Get using the height and commitment
+// encoded in daKey.
+func (c *Client) Retrieve(daKey string) ([]byte, error) {
+	height, commitment, err := parseDAKey(daKey)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := call(c.daRpc, c.rpcAuth, "blob.Get", []interface{}{height, defaultNamespace, commitment})
+	if err != nil {
+		return nil, err
+	}
+
+	var blob types.CelestiaBlob
+	if err = json.Unmarshal(result, &blob); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal blob: %v", err)
+	}
+	return base64.StdEncoding.DecodeString(blob.Data)
+}
+
+// Status reports "included" once the blob can be fetched at its height.
+func (c *Client) Status(daKey string) (string, error) {
+	if _, err := c.Retrieve(daKey); err != nil {
+		return "", err
+	}
+	return "included", nil
+}
+
 const (
 	namespaceVersion    = 0
 	leadingZeroBytes    = 18
 	userSpecifiedBytes  = 11
 	totalNamespaceBytes = leadingZeroBytes + userSpecifiedBytes
+
+	defaultNamespace = "AAAAAAAAAAAAAAAAAAAAAAAAAICj+khUlIv2W7g="
 )
 
+// Celestia submits daData with blob.Submit and returns a DA key of the form
+// "<height>:<commitment>", which is what blob.Get needs to read it back.
 func Celestia(daData []byte, daRpc string, rpcAUTH string) (string, error) {
 
 	//namespace := GenerateNamespace()
 	encodedDataString := base64.StdEncoding.EncodeToString(daData)
 
+	params := []interface{}{
+		[]interface{}{map[string]interface{}{
+			"namespace":     defaultNamespace,
+			"data":          encodedDataString,
+			"share_version": 0,
+			"commitment":    "AD5EzbG0/EMvpw0p8NIjMVnoCP4Bv6K+V6gjmwdXUKU=",
+		}},
+		0.05,
+	}
+
+	result, err := call(daRpc, rpcAUTH, "blob.Submit", params)
+	if err != nil {
+		return "", err
+	}
+
+	var height uint64
+	if err = json.Unmarshal(result, &height); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	// The node computes the share commitment itself, so look the blob up at the
+	// inclusion height to learn it.
+	result, err = call(daRpc, rpcAUTH, "blob.GetAll", []interface{}{height, []string{defaultNamespace}})
+	if err != nil {
+		return "", err
+	}
+
+	var blobs []types.CelestiaBlob
+	if err = json.Unmarshal(result, &blobs); err != nil {
+		return "", fmt.Errorf("failed to unmarshal blobs: %v", err)
+	}
+	for _, blob := range blobs {
+		if blob.Data == encodedDataString {
+			return fmt.Sprintf("%d:%s", height, blob.Commitment), nil
+		}
+	}
+
+	return "", fmt.Errorf("submitted blob not found at height %d", height)
+}
+
+func parseDAKey(daKey string) (uint64, string, error) {
+	heightStr, commitment, found := strings.Cut(daKey, ":")
+	if !found || commitment == "" {
+		return 0, "", fmt.Errorf("celestia DA key %q has no commitment, it can not be retrieved", daKey)
+	}
+	height, err := strconv.ParseUint(heightStr, 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid height in celestia DA key %q: %v", daKey, err)
+	}
+	return height, commitment, nil
+}
+
+func call(daRpc string, rpcAUTH string, method string, params []interface{}) (json.RawMessage, error) {
 	//* Create the payload struct
 	payload := map[string]interface{}{
 		"id":      1,
 		"jsonrpc": "2.0",
-		"method":  "blob.Submit",
-		"params": []interface{}{
-			[]interface{}{map[string]interface{}{
-				"namespace":     "AAAAAAAAAAAAAAAAAAAAAAAAAICj+khUlIv2W7g=",
-				"data":          encodedDataString,
-				"share_version": 0,
-				"commitment":    "AD5EzbG0/EMvpw0p8NIjMVnoCP4Bv6K+V6gjmwdXUKU=",
-			}},
-			0.05,
-		},
+		"method":  method,
+		"params":  params,
 	}
 
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("DA Body Unable to Marshell: %v", err)
+		return nil, fmt.Errorf("DA Body Unable to Marshell: %v", err)
 	}
 
 	client := &http.Client{}
 	req, err := http.NewRequest("POST", daRpc, bytes.NewBuffer(payloadJSON))
 	if err != nil {
-		return "", fmt.Errorf("HTTP Req Error: %v", err)
+		return nil, fmt.Errorf("HTTP Req Error: %v", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -55,35 +166,27 @@ func Celestia(daData []byte, daRpc string, rpcAUTH string) (string, error) {
 
 	response, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("API call failed: %v", err)
+		return nil, fmt.Errorf("API call failed: %v", err)
 	}
 	defer response.Body.Close()
 
 	if response.StatusCode == 401 {
-		return "", fmt.Errorf("unauthorized Token")
+		return nil, fmt.Errorf("unauthorized Token")
 	}
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return "", fmt.Errorf("error reading response body: %v", err)
+		return nil, fmt.Errorf("error reading response body: %v", err)
 	}
 
-	fmt.Println("Raw API Response:", string(body)) // Log the raw response
-
-	// Try parsing as SuccessResponse first
-	var successResponse types.CelestiaSuccessResponse
-	err = json.Unmarshal(body, &successResponse)
-	if err != nil {
-		fmt.Println("Error unmarshalling SuccessResponse:", err) // Detailed error
-		var errorResponse types.CelestiaErrorResponse
-		err = json.Unmarshal(body, &errorResponse)
-		if err != nil {
-			fmt.Println("Error unmarshalling ErrorResponse:", err) // Detailed error
-			return "", fmt.Errorf("failed to unmarshal response: %v", err)
-		} else {
-			return "", fmt.Errorf(errorResponse.Error.Message)
-		}
-	} else {
-		return strconv.Itoa(successResponse.Result), nil
+	var rpcResponse types.CelestiaRPCResponse
+	if err = json.Unmarshal(body, &rpcResponse); err != nil {
+		fmt.Println("Raw API Response:", string(body)) // Log the raw response
+		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+	if rpcResponse.Error != nil {
+		return nil, fmt.Errorf("%s: %s", method, rpcResponse.Error.Message)
 	}
+
+	return rpcResponse.Result, nil
 }
@@ -0,0 +1,97 @@
+package da
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/airchains-network/decentralized-sequencer/config"
+)
+
+// ErrNotSupported is returned by a backend for an operation it cannot perform.
+var ErrNotSupported = errors.New("operation not supported by this DA backend")
+
+// Client is the interface every data availability backend implements. The pod
+// pipeline only talks to the DA layer through this interface, so a new backend
+// can be added by registering it without touching the p2p package.
+type Client interface {
+	// Name returns the client name stored as DAClientName in the DA pointer.
+	Name() string
+	// Submit posts daData for the given pod number and returns the key under
+	// which the blob can be found on the DA layer.
+	Submit(daData []byte, podNumber int) (string, error)
+	// Retrieve returns the blob stored on the DA layer under daKey.
+	Retrieve(daKey string) ([]byte, error)
+	// Status returns the DA layer's status for the blob stored under daKey.
+	Status(daKey string) (string, error)
+}
+
+// Factory builds a Client from the [da] section of sequencer.toml.
+type Factory func(cfg *config.DAConfig) (Client, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a DA backend available under daType, the value operators set
+// as daType in sequencer.toml. It is meant to be called from a backend's init
+// function and panics if the same type is registered twice.
+func Register(daType string, factory Factory) {
+	RegisterWithCheck(daType, factory, nil)
+}
+
+// RegisterWithCheck is Register for a backend with rules for the [da]
+// section of sequencer.toml, which config.Validate applies when daType is
+// the configured type.
+func RegisterWithCheck(daType string, factory Factory, check config.DAConfigCheck) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	daType = strings.ToLower(daType)
+	if factory == nil {
+		panic("da: Register factory is nil for " + daType)
+	}
+	if _, dup := registry[daType]; dup {
+		panic("da: Register called twice for " + daType)
+	}
+	registry[daType] = factory
+	config.RegisterDAType(daType, check)
+}
+
+// IsRegistered reports whether a backend is registered for daType.
+func IsRegistered(daType string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := registry[strings.ToLower(daType)]
+	return ok
+}
+
+// Registered returns the sorted list of registered DA types.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	types := make([]string, 0, len(registry))
+	for daType := range registry {
+		types = append(types, daType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NewClient returns the client registered for cfg.DaType.
+func NewClient(cfg *config.DAConfig) (Client, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("da config is missing")
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[strings.ToLower(cfg.DaType)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown DA type %q, must be one of: %s", cfg.DaType, strings.Join(Registered(), ", "))
+	}
+	return factory(cfg)
+}
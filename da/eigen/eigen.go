@@ -40,21 +40,112 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/airchains-network/decentralized-sequencer/da"
 	disperserGrpc "github.com/airchains-network/decentralized-sequencer/da/eigen/grpc"
 	"github.com/airchains-network/decentralized-sequencer/da/eigen/utils"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"strings"
 	"time"
 )
 
-func Eigen(daData []byte, rpcUrl string, accountKey string) (string, error) {
-	ctx := context.Background()
+// ClientName is stored as DAClientName for pods posted to EigenDA.
+const ClientName = "eigen-da"
+
+func init() {
+	da.RegisterWithCheck("eigen", func(cfg *config.DAConfig) (da.Client, error) {
+		if cfg.DaRPC == "" {
+			return nil, fmt.Errorf("daRPC is required for eigen")
+		}
+		return &Client{rpcUrl: cfg.DaRPC, accountKey: cfg.DaKey}, nil
+	}, func(c *config.DAConfig, problems config.DAProblems) {
+		// the eigen client dials <daRPC>:443 itself
+		if c.DaRPC == "" || strings.Contains(c.DaRPC, "://") || strings.Contains(c.DaRPC, ":") {
+			problems.Addf("da.daRPC", "%q must be the host name of the eigen disperser, without scheme or port", c.DaRPC)
+		}
+	})
+}
+
+// Client is the da.Client for the EigenDA disperser gRPC API.
+type Client struct {
+	rpcUrl     string
+	accountKey string
+}
+
+func (c *Client) Name() string {
+	return ClientName
+}
+
+func (c *Client) Submit(daData []byte, _ int) (string, error) {
+	return Eigen(daData, c.rpcUrl, c.accountKey)
+}
+
+// Retrieve looks up the batch header hash and blob index of the dispersal
+// request in daKey and fetches the blob with RetrieveBlob.
+func (c *Client) Retrieve(daKey string) ([]byte, error) {
+	conn, err := dial(c.rpcUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	disperserClient := disperserGrpc.NewDisperserClient(conn)
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	statusReply, err := disperserClient.GetBlobStatus(ctxTimeout, &disperserGrpc.BlobStatusRequest{RequestId: []byte(daKey)})
+	if err != nil {
+		return nil, err
+	}
+	status := statusReply.GetStatus()
+	if status != disperserGrpc.BlobStatus_CONFIRMED && status != disperserGrpc.BlobStatus_FINALIZED {
+		return nil, fmt.Errorf("blob is not confirmed yet, status: %s", status)
+	}
+
+	proof := statusReply.GetInfo().GetBlobVerificationProof()
+	reply, err := disperserClient.RetrieveBlob(ctxTimeout, &disperserGrpc.RetrieveBlobRequest{
+		BatchHeaderHash: proof.GetBatchMetadata().GetBatchHeaderHash(),
+		BlobIndex:       proof.GetBlobIndex(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.RemoveEmptyByteFromPaddedBytes(reply.GetData()), nil
+}
+
+// Status returns the disperser's current status name for the blob request.
+func (c *Client) Status(daKey string) (string, error) {
+	conn, err := dial(c.rpcUrl)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+
+	disperserClient := disperserGrpc.NewDisperserClient(conn)
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reply, err := disperserClient.GetBlobStatus(ctxTimeout, &disperserGrpc.BlobStatusRequest{RequestId: []byte(daKey)})
+	if err != nil {
+		return "", err
+	}
+	return reply.GetStatus().String(), nil
+}
 
+func dial(rpcUrl string) (*grpc.ClientConn, error) {
 	credential := credentials.NewTLS(&tls.Config{})
 	addr := fmt.Sprintf("%v:%v", rpcUrl, 443)
 	dialOptions := grpc.WithTransportCredentials(credential)
-	conn, err := grpc.Dial(addr, dialOptions)
+	return grpc.Dial(addr, dialOptions)
+}
+
+func Eigen(daData []byte, rpcUrl string, accountKey string) (string, error) {
+	ctx := context.Background()
+
+	conn, err := dial(rpcUrl)
 	if err != nil {
 		log.Err(err).Msg("failed to dial")
 		return "nil", err
@@ -64,7 +155,7 @@ func Eigen(daData []byte, rpcUrl string, accountKey string) (string, error) {
 	d, de := DisperserBlob(ctx, conn, daData, accountKey)
 	if de != nil {
 		log.Error().Err(de).Msg("failed to disperse blob")
-		return "nil", err
+		return "nil", de
 	}
 
 	blobKey := string(d[:])
@@ -120,8 +211,7 @@ func GetStatus(conn *grpc.ClientConn, requestID []byte, ctx context.Context) (*d
 			return nil, err
 		}
 
-		check := disperserGrpc.BlobStatus_CONFIRMED
-		if reply.Status.Enum() == &check {
+		if reply.GetStatus() == disperserGrpc.BlobStatus_CONFIRMED {
 			return reply.Status.Enum(), nil
 		}
 
@@ -28,3 +28,30 @@ func ConvertByPaddingEmptyByte(data []byte) []byte {
 	}
 	return validData[:validEnd]
 }
+
+// RemoveEmptyByteFromPaddedBytes reverses ConvertByPaddingEmptyByte by dropping
+// the leading empty byte of every symbol.
+func RemoveEmptyByteFromPaddedBytes(data []byte) []byte {
+	dataSize := len(data)
+	parseSize := BYTES_PER_SYMBOL
+	dataLen := (dataSize + parseSize - 1) / parseSize
+
+	putSize := BYTES_PER_SYMBOL - 1
+
+	validData := make([]byte, dataLen*putSize)
+	validLen := len(validData)
+
+	for i := 0; i < dataLen; i++ {
+		// add 1 to leave the first empty byte untouched
+		start := i*parseSize + 1
+		end := (i + 1) * parseSize
+
+		if end > len(data) {
+			end = len(data)
+			validLen = end - start + i*putSize
+		}
+
+		copy(validData[i*putSize:(i+1)*putSize], data[start:end])
+	}
+	return validData[:validLen]
+}
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPaddingRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 30, 31, 32, 62, 100} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i + 1)
+		}
+		got := RemoveEmptyByteFromPaddedBytes(ConvertByPaddingEmptyByte(data))
+		if !bytes.Equal(got, data) {
+			t.Fatalf("size %d: round trip mismatch, got %x want %x", size, got, data)
+		}
+	}
+}
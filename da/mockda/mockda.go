@@ -3,13 +3,74 @@ package mock
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/blocksync"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/airchains-network/decentralized-sequencer/da"
+	"github.com/airchains-network/decentralized-sequencer/store"
 	"github.com/airchains-network/decentralized-sequencer/types"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
-// MockDA is a function that mocks the functionality of storing data in a mock database (leveldb). It takes the following parameters:
-// - mdb: a pointer to a leveldb.DB instance representing the mock database
+// ClientName is stored as DAClientName for pods posted to the mock DA.
+const ClientName = "mock-da"
+
+func init() {
+	da.Register("mock", func(_ *config.DAConfig) (da.Client, error) {
+		db := blocksync.GetStore()
+		if db == nil {
+			return nil, fmt.Errorf("mock db is not initialized")
+		}
+		return NewClient(db.Mock()), nil
+	})
+}
+
+// Client is the da.Client backed by the local mock database.
+type Client struct {
+	mdb store.Namespace
+}
+
+// NewClient returns a mock DA client storing blobs in mdb.
+func NewClient(mdb store.Namespace) *Client {
+	return &Client{mdb: mdb}
+}
+
+func (c *Client) Name() string {
+	return ClientName
+}
+
+func (c *Client) Submit(daData []byte, podNumber int) (string, error) {
+	return MockDA(c.mdb, daData, podNumber)
+}
+
+// Retrieve returns the blob stored under daKey in the mock database.
+func (c *Client) Retrieve(daKey string) ([]byte, error) {
+	byteMockData, err := c.mdb.Get([]byte(daKey))
+	if err != nil {
+		return nil, fmt.Errorf("error getting data from mock db: %v", err)
+	}
+
+	var mockData types.MockDAStruck
+	if err = json.Unmarshal(byteMockData, &mockData); err != nil {
+		return nil, fmt.Errorf("error decoding mock da data %s: %v", daKey, err)
+	}
+	return mockData.DataBlob, nil
+}
+
+// Status reports "stored" once the blob is present in the mock database.
+func (c *Client) Status(daKey string) (string, error) {
+	ok, err := c.mdb.Has([]byte(daKey))
+	if err != nil {
+		return "", fmt.Errorf("error reading mock db: %v", err)
+	}
+	if !ok {
+		return "", fmt.Errorf("blob %s not found in mock db", daKey)
+	}
+	return "stored", nil
+}
+
+// MockDA is a function that mocks the functionality of storing data in a mock database. It takes the following parameters:
+// - mdb: the mock namespace of the store
 // - daData: a byte slice containing the data to be stored
 // - batchNumber: an integer representing the batch number
 //
@@ -17,12 +78,12 @@ import (
 // 1. Computes the SHA256 hash of daData.
 // 2. Encodes the hash as a string using hexadecimal encoding.
 // 3. Creates a new types.MockDAStruck instance with the daData, batchNumber, and computed hashString.
-// 4. Converts the mockData into a byte slice.
+// 4. Encodes the mockData as JSON.
 // 5. Generates a unique database name based on the batchNumber.
 // 6. Stores the byteMockData in the mock database using the dbName as the key.
 // 7. Returns the dbName and nil error if the operation is successful.
 // 8. Otherwise, returns an empty string and an error message indicating the failure.
-func MockDA(mdb *leveldb.DB, daData []byte, batchNumber int) (string, error) {
+func MockDA(mdb store.Namespace, daData []byte, batchNumber int) (string, error) {
 
 	hash := sha256.Sum256(daData)
 	hashString := hex.EncodeToString(hash[:])
@@ -33,15 +94,16 @@ func MockDA(mdb *leveldb.DB, daData []byte, batchNumber int) (string, error) {
 		Commitment:  hashString,
 	}
 
-	byteMockData := []byte(fmt.Sprintf("%v", mockData))
+	byteMockData, err := json.Marshal(mockData)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling mock da data: %v", err)
+	}
 
 	dbName := fmt.Sprintf("mockda-%d", batchNumber)
-	dbErr := mdb.Put([]byte(dbName), byteMockData, nil)
+	dbErr := mdb.Put([]byte(dbName), byteMockData)
 	if dbErr != nil {
 		return "", fmt.Errorf("error putting data into mock db: %v", dbErr)
 	}
 
-	_ = fmt.Sprintf("da_id : %d, commitment : %s", dbName, hashString)
-
 	return dbName, nil
 }
@@ -0,0 +1,51 @@
+package da
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/airchains-network/decentralized-sequencer/types"
+)
+
+// ErrCommitmentMismatch is returned by Verify when the blob read back from the
+// DA layer does not hash to the commitment recorded for the pod.
+var ErrCommitmentMismatch = errors.New("DA blob does not match the recorded commitment")
+
+// Commitment returns the hex encoded sha256 of daData. It is recorded in the
+// DA pointer of every pod so the blob can be checked after it is read back.
+func Commitment(daData []byte) string {
+	hash := sha256.Sum256(daData)
+	return hex.EncodeToString(hash[:])
+}
+
+// Verify reads back the blob referenced by pointer and checks it against the
+// recorded commitment. It returns the blob when the commitment matches.
+func Verify(client Client, pointer types.DAStruct) ([]byte, error) {
+	if pointer.DAClientName != client.Name() {
+		return nil, fmt.Errorf("pod was posted to %s but the configured DA client is %s", pointer.DAClientName, client.Name())
+	}
+	if pointer.Commitment == "" {
+		return nil, fmt.Errorf("no commitment recorded for pod %s", pointer.BatchNumber)
+	}
+
+	daData, err := client.Retrieve(pointer.DAKey)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving blob %s: %w", pointer.DAKey, err)
+	}
+
+	// Some DA layers (EigenDA) hand the blob back padded to their symbol size.
+	if pointer.DataLength > 0 && len(daData) > pointer.DataLength {
+		if len(bytes.Trim(daData[pointer.DataLength:], "\x00")) != 0 {
+			return nil, ErrCommitmentMismatch
+		}
+		daData = daData[:pointer.DataLength]
+	}
+
+	if Commitment(daData) != pointer.Commitment {
+		return nil, ErrCommitmentMismatch
+	}
+	return daData, nil
+}
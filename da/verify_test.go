@@ -0,0 +1,59 @@
+package da_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/airchains-network/decentralized-sequencer/da"
+	"github.com/airchains-network/decentralized-sequencer/types"
+)
+
+type staticClient struct {
+	blob []byte
+}
+
+func (c *staticClient) Name() string { return "static-da" }
+
+func (c *staticClient) Submit(daData []byte, _ int) (string, error) {
+	c.blob = daData
+	return "static-1", nil
+}
+
+func (c *staticClient) Retrieve(string) ([]byte, error) { return c.blob, nil }
+
+func (c *staticClient) Status(string) (string, error) { return "stored", nil }
+
+func TestVerify(t *testing.T) {
+	blob := []byte("pod-1 transactions")
+	pointer := types.DAStruct{
+		DAKey:        "static-1",
+		DAClientName: "static-da",
+		BatchNumber:  "1",
+		Commitment:   da.Commitment(blob),
+		DataLength:   len(blob),
+	}
+
+	client := &staticClient{blob: blob}
+	if _, err := da.Verify(client, pointer); err != nil {
+		t.Fatalf("expected blob to verify, got %v", err)
+	}
+
+	client.blob = append(append([]byte{}, blob...), 0, 0, 0)
+	got, err := da.Verify(client, pointer)
+	if err != nil {
+		t.Fatalf("expected zero padded blob to verify, got %v", err)
+	}
+	if string(got) != string(blob) {
+		t.Fatalf("expected padding to be trimmed, got %q", got)
+	}
+
+	client.blob = []byte("pod-1 tampered data")
+	if _, err := da.Verify(client, pointer); !errors.Is(err, da.ErrCommitmentMismatch) {
+		t.Fatalf("expected ErrCommitmentMismatch, got %v", err)
+	}
+
+	pointer.DAClientName = "mock-da"
+	if _, err := da.Verify(client, pointer); err == nil {
+		t.Fatal("expected error for pointer posted to another DA client")
+	}
+}
@@ -18,7 +18,6 @@ import (
 	"github.com/ignite/cli/v28/ignite/pkg/cosmosaccount"
 	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
 	"os"
-	"path/filepath"
 
 	"time"
 )
@@ -159,16 +158,10 @@ func CreateStation(extraArg junctionTypes.StationArg, stationId string, stationI
 	}
 	logs.Log.Info("Successfully Created VRF public and private Keys")
 
-	homeDir, err := os.UserHomeDir()
+	ConfigFilePath := config.ConfigPath(config.DefaultConfigFileName)
+	conf, err := config.Load(ConfigFilePath)
 	if err != nil {
-		logs.Log.Error("Error in getting home dir path: " + err.Error())
-		return false
-	}
-
-	ConfigFilePath := filepath.Join(homeDir, config.DefaultTracksDir, config.DefaultConfigDir, config.DefaultConfigFileName)
-	bytes, err := os.ReadFile(ConfigFilePath)
-	if err != nil {
-		logs.Log.Error("Error reading sequencer.toml")
+		logs.Log.Error(fmt.Sprintf("Error reading sequencer.toml: %v", err))
 		return false
 	}
 
@@ -253,17 +246,13 @@ func CreateStation(extraArg junctionTypes.StationArg, stationId string, stationI
 	//
 	//fmt.Println(check)
 
-	var conf config.Config // JunctionConfig
-	if err = toml.Unmarshal(bytes, &conf); err != nil {
-		logs.Log.Error(fmt.Sprintf("Error unmarshalling config: %v", err))
-		return false
-	}
-
 	//fmt.Println(conf.P2P)
 
 	// Update the values
 
 	conf.P2P.PersistentPeers = bootstrapNode
+	// the bootstrap node also seeds the DHT when discovery is enabled
+	conf.P2P.BootstrapPeers = bootstrapNode
 	conf.Junction.JunctionRPC = jsonRPC
 	conf.Junction.JunctionAPI = ""
 	conf.Junction.StationId = stationId
@@ -291,6 +280,7 @@ func CreateStation(extraArg junctionTypes.StationArg, stationId string, stationI
 		logs.Log.Error(fmt.Sprintf("Error encoding config: %v", err))
 		return false
 	}
+	config.SetCurrent(conf)
 
 	return true
 
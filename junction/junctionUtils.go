@@ -9,7 +9,6 @@ import (
 	"github.com/airchains-network/decentralized-sequencer/config"
 	junctionTypes "github.com/airchains-network/decentralized-sequencer/junction/types"
 	logs "github.com/airchains-network/decentralized-sequencer/log"
-	"github.com/airchains-network/decentralized-sequencer/node/shared"
 	"github.com/airchains-network/decentralized-sequencer/types"
 	"github.com/consensys/gnark/backend/groth16"
 	"go.dedis.ch/kyber/v3"
@@ -41,13 +40,7 @@ func CreateGenesisJson(stationInfo types.StationInfo, verificationKey groth16.Ve
 		return false
 	}
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		logs.Log.Error("Error in getting home dir path: " + err.Error())
-		return false
-	}
-
-	GenesisFilePath := filepath.Join(homeDir, config.DefaultGenesisFilePath)
+	GenesisFilePath := config.ConfigPath(config.DefaultGenesisFileName)
 
 	// Write the JSON data to a file
 	err = os.WriteFile(GenesisFilePath, jsonBytes, 0644)
@@ -75,12 +68,7 @@ func NewKeyPair() (privateKeyX kyber.Scalar, publicKeyX kyber.Point) {
 }
 
 func SetVRFPubKey(pubKey string) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		logs.Log.Error("Error in getting home dir path: " + err.Error())
-	}
-
-	ConfigFilePath := filepath.Join(homeDir, config.DefaultTracksDir, config.DefaultConfigDir)
+	ConfigFilePath := config.ConfigPath()
 	VRFPubKeyPath := filepath.Join(ConfigFilePath, "vrfPubKey.txt")
 	file, err := os.Create(VRFPubKeyPath)
 	if err != nil {
@@ -111,11 +99,7 @@ func SetVRFPubKey(pubKey string) {
 }
 
 func SetVRFPrivKey(privateKey string) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		logs.Log.Error("Error in getting home dir path: " + err.Error())
-	}
-	ConfigFilePath := filepath.Join(homeDir, config.DefaultTracksDir, config.DefaultConfigDir)
+	ConfigFilePath := config.ConfigPath()
 	VRFPrivKeyPath := filepath.Join(ConfigFilePath, "vrfPrivKey.txt")
 	file, err := os.Create(VRFPrivKeyPath)
 	if err != nil {
@@ -146,12 +130,7 @@ func SetVRFPrivKey(privateKey string) {
 }
 
 func GetVRFPrivateKey() (privateKey string) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		logs.Log.Error("Error in getting home dir path: " + err.Error())
-	}
-
-	ConfigFilePath := filepath.Join(homeDir, config.DefaultTracksDir, config.DefaultConfigDir)
+	ConfigFilePath := config.ConfigPath()
 	VRFPrivKeyPath := filepath.Join(ConfigFilePath, "vrfPrivKey.txt")
 	file, err := os.Open(VRFPrivKeyPath)
 	if err != nil {
@@ -177,12 +156,7 @@ func GetVRFPrivateKey() (privateKey string) {
 
 func GetVRFPubKey() (pubKey string) {
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		logs.Log.Error("Error in getting home dir path: " + err.Error())
-	}
-
-	ConfigFilePath := filepath.Join(homeDir, config.DefaultTracksDir, config.DefaultConfigDir)
+	ConfigFilePath := config.ConfigPath()
 	VRFPubKeyPath := filepath.Join(ConfigFilePath, "vrfPubKey.txt")
 
 	// get private Key
@@ -272,7 +246,7 @@ func GenerateVRFProof(suite kyber.Group, privateKey kyber.Scalar, data []byte, n
 func GetJunctionDetails() (JsonRPC, StationId, AccountPath, AccountName, AddressPrefix string, Tracks []string, err error) {
 	// Specify the file path and name
 
-	baseConfig, err := shared.LoadConfig()
+	baseConfig, err := config.Current()
 	if err != nil {
 		errorMsg := fmt.Errorf("error in aloading config file")
 		return "", "", "", "", "", Tracks, errorMsg
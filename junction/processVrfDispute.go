@@ -0,0 +1,143 @@
+package junction
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/junction/types"
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/airchains-network/decentralized-sequencer/node/shared"
+	utilis "github.com/airchains-network/decentralized-sequencer/utils"
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosaccount"
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosclient"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"os"
+	"time"
+)
+
+// disputeRetries bounds the ProcessVrfDispute transactions: a dispute that
+// can not be submitted is left to the next round of the pod.
+const disputeRetries = 3
+
+// VrfDisputeVoteBytes is what a track signs with its junction key to vote
+// on the VRN of a disputed pod.
+func VrfDisputeVoteBytes(stationId string, podNumber uint64, vote bool) []byte {
+	return []byte(fmt.Sprintf("vrf-dispute/%s/%d/%t", stationId, podNumber, vote))
+}
+
+// CheckVrfRecord recomputes the VRF of record from its proof and the VRF
+// public key of its creator, as GenerateVRFProof made them. upperBound is
+// the number of tracks of the station.
+func CheckVrfRecord(record *types.VrfRecord, upperBound uint64) error {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	publicKeyBytes, err := hex.DecodeString(record.CreatorsVrfKey)
+	if err != nil {
+		return fmt.Errorf("invalid VRF key of %s: %v", record.VrfCreatorAddr, err)
+	}
+	publicKey := suite.Point()
+	if err = publicKey.UnmarshalBinary(publicKeyBytes); err != nil {
+		return fmt.Errorf("invalid VRF key of %s: %v", record.VrfCreatorAddr, err)
+	}
+
+	pointLen := suite.PointLen()
+	if len(record.Proof) != pointLen+suite.ScalarLen() {
+		return fmt.Errorf("VRF proof is %d bytes, want %d", len(record.Proof), pointLen+suite.ScalarLen())
+	}
+	rBytes := record.Proof[:pointLen]
+	R := suite.Point()
+	if err = R.UnmarshalBinary(rBytes); err != nil {
+		return fmt.Errorf("invalid VRF proof: %v", err)
+	}
+	s := suite.Scalar()
+	if err = s.UnmarshalBinary(record.Proof[pointLen:]); err != nil {
+		return fmt.Errorf("invalid VRF proof: %v", err)
+	}
+
+	// g^s = R + e*publicKey, with e = H(R||rc)
+	hash := sha256.New()
+	hash.Write(rBytes)
+	hash.Write(record.SerializedRcFromCreator)
+	e := suite.Scalar().SetBytes(hash.Sum(nil))
+	expected := suite.Point().Add(R, suite.Point().Mul(e, publicKey))
+	if !suite.Point().Mul(s, nil).Equal(expected) {
+		return fmt.Errorf("VRF proof is not signed by the VRF key of %s", record.VrfCreatorAddr)
+	}
+
+	output := sha256.Sum256(append(append([]byte{}, rBytes...), record.SerializedRcFromCreator...))
+	if !bytes.Equal(output[:], record.VrfOutput) {
+		return fmt.Errorf("VRF output does not match its proof")
+	}
+	if record.SelectedTrackIndex >= upperBound {
+		return fmt.Errorf("selected track %d of %d tracks", record.SelectedTrackIndex, upperBound)
+	}
+	return nil
+}
+
+// ProcessVrfDispute submits the votes of the tracks on the VRN of the
+// current pod, and returns whether the junction accepted the dispute.
+func ProcessVrfDispute(signatures [][]byte, votes []bool, publicKeys [][]byte) (success bool) {
+	zerolog.TimeFieldFormat = time.RFC3339
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	jsonRpc, stationId, accountPath, accountName, addressPrefix, _, err := GetJunctionDetails()
+	if err != nil {
+		logs.Log.Error("can not get junctionDetails.json data: " + err.Error())
+		return false
+	}
+
+	registry, err := cosmosaccount.New(cosmosaccount.WithHome(accountPath))
+	if err != nil {
+		logs.Log.Error(fmt.Sprintf("Error creating account registry: %v", err))
+		return false
+	}
+
+	newTempAccount, err := registry.GetByName(accountName)
+	if err != nil {
+		logs.Log.Error(fmt.Sprintf("Error getting account: %v", err))
+		return false
+	}
+
+	newTempAddr, err := newTempAccount.Address(addressPrefix)
+	if err != nil {
+		logs.Log.Error(fmt.Sprintf("Error getting address: %v", err))
+		return false
+	}
+
+	ctx := context.Background()
+	gas := utilis.GenerateRandomWithFavour(510, 1000, [2]int{520, 700}, 0.7)
+	gasFees := fmt.Sprintf("%damf", gas)
+	log.Info().Str("module", "junction").Str("Gas Fees Used to Process VRF Dispute", gasFees)
+	accountClient, err := cosmosclient.New(ctx, cosmosclient.WithAddressPrefix(addressPrefix), cosmosclient.WithNodeAddress(jsonRpc), cosmosclient.WithHome(accountPath), cosmosclient.WithGas("auto"), cosmosclient.WithFees(gasFees))
+	if err != nil {
+		logs.Log.Error("Switchyard client connection error")
+		logs.Log.Error(err.Error())
+		return false
+	}
+
+	podNumber := shared.GetPodState().LatestPodHeight
+	msg := types.NewMsgProcessVrfDispute(newTempAddr, podNumber, stationId, signatures, votes, publicKeys)
+
+	for try := 1; ; try++ {
+		txRes, errTxRes := accountClient.BroadcastTx(ctx, newTempAccount, msg)
+		if errTxRes != nil {
+			log.Error().Str("module", "junction").Str("Error", errTxRes.Error()).Msg("Error in ProcessVrfDispute transaction")
+			if try == disputeRetries {
+				return false
+			}
+			log.Debug().Str("module", "junction").Msg("Retrying ProcessVrfDispute transaction after 10 seconds..")
+			time.Sleep(10 * time.Second)
+			continue
+		}
+		var res types.MsgProcessVrfDisputeResponse
+		if err = txRes.Decode(&res); err != nil {
+			logs.Log.Error("Error decoding ProcessVrfDispute response: " + err.Error())
+			return false
+		}
+		log.Info().Str("module", "junction").Str("txHash", txRes.TxHash).Bool("success", res.Success).Msg("VRF Dispute Tx Success")
+		return res.Success
+	}
+}
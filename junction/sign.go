@@ -0,0 +1,80 @@
+package junction
+
+import (
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosaccount"
+)
+
+// Signer signs messages exchanged between tracks with the junction account
+// of this track, so other tracks can check the sender is in the station's
+// Tracks list.
+type Signer struct {
+	keyring     keyring.Keyring
+	accountName string
+	address     string
+}
+
+// NewSigner opens the junction account of the config.
+func NewSigner() (*Signer, error) {
+	_, _, accountPath, accountName, addressPrefix, _, err := GetJunctionDetails()
+	if err != nil {
+		return nil, err
+	}
+	registry, err := cosmosaccount.New(cosmosaccount.WithHome(accountPath))
+	if err != nil {
+		return nil, fmt.Errorf("error creating account registry: %v", err)
+	}
+	return NewSignerFromRegistry(registry, accountName, addressPrefix)
+}
+
+// NewSignerFromRegistry signs with the account accountName of registry.
+func NewSignerFromRegistry(registry cosmosaccount.Registry, accountName string, addressPrefix string) (*Signer, error) {
+	account, err := registry.GetByName(accountName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting account %s: %v", accountName, err)
+	}
+	address, err := account.Address(addressPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("error getting address: %v", err)
+	}
+	return &Signer{keyring: registry.Keyring, accountName: accountName, address: address}, nil
+}
+
+// Address is the junction address of this track.
+func (s *Signer) Address() string {
+	return s.address
+}
+
+// Sign signs msg and returns the compressed secp256k1 public key with the
+// signature.
+func (s *Signer) Sign(msg []byte) (pubKey []byte, signature []byte, err error) {
+	signature, pk, err := s.keyring.Sign(s.accountName, msg, signing.SignMode_SIGN_MODE_DIRECT)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error signing with %s: %v", s.accountName, err)
+	}
+	return pk.Bytes(), signature, nil
+}
+
+// VerifySignature checks that signature was made over msg by the key of
+// address.
+func VerifySignature(address string, addressPrefix string, pubKey []byte, msg []byte, signature []byte) error {
+	if len(pubKey) != secp256k1.PubKeySize {
+		return fmt.Errorf("public key must be %d bytes, got %d", secp256k1.PubKeySize, len(pubKey))
+	}
+	pk := &secp256k1.PubKey{Key: pubKey}
+	keyAddress, err := bech32.ConvertAndEncode(addressPrefix, pk.Address())
+	if err != nil {
+		return err
+	}
+	if keyAddress != address {
+		return fmt.Errorf("public key belongs to %s, not %s", keyAddress, address)
+	}
+	if !pk.VerifySignature(msg, signature) {
+		return fmt.Errorf("invalid signature of %s", address)
+	}
+	return nil
+}
@@ -119,8 +119,9 @@ func SubmitCurrentPod() (success bool) {
 			//return false
 		} else {
 			// update txHash of submit pod in pod state
-			currentPodState.InitPodTxHash = txRes.TxHash
-			shared.SetPodState(currentPodState)
+			shared.UpdatePodState(func(podState *shared.PodState) {
+				podState.InitPodTxHash = txRes.TxHash
+			})
 			log.Info().Str("module", "junction").Str("txHash", txRes.TxHash).Msg("Pod submitted successfully")
 			return true
 		}
@@ -107,8 +107,9 @@ func ValidateVRF(addr string) bool {
 			}
 		} else {
 			// update VRN verified hash
-			currentPodState.VRFValidationTxHash = txRes.TxHash
-			shared.SetPodState(currentPodState)
+			shared.UpdatePodState(func(podState *shared.PodState) {
+				podState.VRFValidationTxHash = txRes.TxHash
+			})
 			log.Info().Str("module", "junction").Str("txHash", txRes.TxHash).Msg("VRF Validated Tx Success")
 			return true
 		}
@@ -111,9 +111,9 @@ func VerifyCurrentPod() (success bool) {
 			time.Sleep(10 * time.Second)
 			//return false
 		} else {
-			VerifyPodTxHash := txRes.TxHash
-			currentPodState.VerifyPodTxHash = VerifyPodTxHash
-			shared.SetPodState(currentPodState)
+			shared.UpdatePodState(func(podState *shared.PodState) {
+				podState.VerifyPodTxHash = txRes.TxHash
+			})
 			log.Info().Str("module", "junction").Str("txHash", txRes.TxHash).Msg("Pod Verification Tx Success")
 			return true
 		}
@@ -4,20 +4,29 @@ import (
 	"context"
 	"fmt"
 	"github.com/airchains-network/decentralized-sequencer/blocksync"
+	"github.com/airchains-network/decentralized-sequencer/config"
 	logs "github.com/airchains-network/decentralized-sequencer/log"
 	"github.com/airchains-network/decentralized-sequencer/node/shared"
 	"github.com/airchains-network/decentralized-sequencer/p2p"
 	"github.com/airchains-network/decentralized-sequencer/rpc"
+	"github.com/airchains-network/decentralized-sequencer/store"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/syndtr/goleveldb/leveldb"
+	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 )
 
+// Start runs the node until it receives SIGINT or SIGTERM, and returns once
+// P2P, the indexer, pod generation and RPC have stopped.
 func Start() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	var wg1 sync.WaitGroup
 	wg1.Add(2)
-	go configureP2P(&wg1)
+	go configureP2P(ctx, &wg1)
 
 	go func() {
 		time.Sleep(5 * time.Second)
@@ -25,31 +34,35 @@ func Start() {
 		defer ticker.Stop()
 		for {
 			select {
+			case <-ctx.Done():
+				wg1.Done()
+				return
 			case <-ticker.C:
 				if p2p.PeerConnectionStatus(p2p.Node) {
-					beginDBIndexingOperations(&wg1)
+					beginDBIndexingOperations(ctx, &wg1)
 					return
 				}
 			}
 		}
 	}()
 	wg1.Wait()
+	logs.Log.Info("Node stopped")
 }
 
-func configureP2P(wg *sync.WaitGroup) {
+func configureP2P(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
-	p2p.P2PConfiguration()
+	p2p.P2PConfiguration(ctx)
 }
 
-func beginDBIndexingOperations(wg *sync.WaitGroup) {
+func beginDBIndexingOperations(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
-	connection := shared.Node.NodeConnections
-	staticDB := connection.GetStaticDatabaseConnection()
-	blockDB := connection.GetBlockDatabaseConnection()
-	txnDB := connection.GetTxnDatabaseConnection()
+	db := shared.Node.Store
+	staticDB := db.Static()
+	blockDB := db.Blocks()
+	txnDB := db.Txns()
 	shared.CheckAndInitializeDBCounters(staticDB)
 	latestBlock := shared.GetLatestBlock(blockDB)
-	baseConfig, err := shared.LoadConfig()
+	baseConfig, err := config.Current()
 
 	client, err := ethclient.Dial(baseConfig.Station.StationRPC) // viper.GetString("station.stationRPC"))
 	if err != nil {
@@ -59,23 +72,21 @@ func beginDBIndexingOperations(wg *sync.WaitGroup) {
 	initializeCounter(staticDB, "batchCount")
 	initializeCounter(staticDB, "batchStartIndex")
 
-	var ctx context.Context
-	ctx = context.Background()
 	var wgnm *sync.WaitGroup
 	wgnm = &sync.WaitGroup{}
 	//wgnm.Add(1)
 	wgnm.Add(3)
 
 	go blocksync.StartIndexer(wgnm, client, ctx, blockDB, txnDB, latestBlock)
-	go p2p.BatchGeneration(wgnm)
-	go rpc.StartRPC(wgnm)
+	go p2p.BatchGeneration(ctx, wgnm)
+	go rpc.StartRPC(ctx, wgnm)
 	wgnm.Wait()
 }
 
-func initializeCounter(staticDB *leveldb.DB, counterName string) {
-	_, err := staticDB.Get([]byte(counterName), nil)
+func initializeCounter(staticDB store.Namespace, counterName string) {
+	_, err := staticDB.Get([]byte(counterName))
 	if err != nil {
-		err = staticDB.Put([]byte(counterName), []byte("0"), nil)
+		err = staticDB.Put([]byte(counterName), []byte("0"))
 		if err != nil {
 			logs.Log.Error(fmt.Sprintf("Error in saving %s in static db: %s", counterName, err.Error()))
 		}
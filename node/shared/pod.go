@@ -6,12 +6,11 @@ import (
 	"fmt"
 	"github.com/airchains-network/decentralized-sequencer/blocksync"
 	"github.com/airchains-network/decentralized-sequencer/config"
+	junctionTypes "github.com/airchains-network/decentralized-sequencer/junction/types"
 	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/airchains-network/decentralized-sequencer/store"
 	"github.com/airchains-network/decentralized-sequencer/types"
-	"github.com/pelletier/go-toml"
-	"github.com/syndtr/goleveldb/leveldb"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -33,7 +32,10 @@ var (
 type Votes struct {
 	PeerID string // TODO change this type to proper Peer ID Type
 	//Commitment string
-	Vote bool
+	Vote bool // the voter derived the same TracksAppHash as this track
+	// Address is the junction address that signed the vote.
+	Address       string
+	TracksAppHash []byte
 }
 type PodState struct {
 	LatestPodHeight     uint64
@@ -52,29 +54,26 @@ type PodState struct {
 	VRFValidationTxHash string
 	InitPodTxHash       string
 	VerifyPodTxHash     string
-}
-type Connections struct {
-	mu                                 sync.Mutex
-	BlockDatabaseConnection            *leveldb.DB
-	TxnDatabaseConnection              *leveldb.DB
-	PodsDatabaseConnection             *leveldb.DB
-	DataAvailabilityDatabaseConnection *leveldb.DB
-	StaticDatabaseConnection           *leveldb.DB
-	StateDatabaseConnection            *leveldb.DB
-	MockDatabaseConnection             *leveldb.DB
-	PublicWitnessConnection            *leveldb.DB
-}
 
+	// StepSenders is the junction address of the track expected to send
+	// each step message of the pod, by message type, as the step before it
+	// selected. It is replaced, never written to, once set.
+	StepSenders map[string]string `json:",omitempty"`
+
+	// VrfDisputeResult is set when the tracks voted on the VRN of the pod
+	// after the junction failed to verify it.
+	VrfDisputeResult *junctionTypes.VrfDisputeResult `json:",omitempty"`
+}
 type NodeS struct {
-	Config          *config.Config
-	podState        *PodState
-	NodeConnections *Connections
+	Config   *config.Config
+	podState *PodState
+	Store    store.Store
 }
 
-func InitializePodState(stateConnection *leveldb.DB) *PodState {
+func InitializePodState(stateConnection store.Namespace) *PodState {
 
 	// sync pod state from database
-	podStateByte, err := stateConnection.Get([]byte("podState"), nil)
+	podStateByte, err := stateConnection.Get([]byte("podState"))
 	if err != nil {
 		fmt.Println(err)
 		logs.Log.Error("Pod should be already initiated/updated by now")
@@ -100,78 +99,42 @@ func SetPodState(podState *PodState) {
 	Node.podState = podState
 }
 
-func InitializeDatabaseConnections() *Connections {
-	return &Connections{
-		BlockDatabaseConnection:            blocksync.GetBlockDbInstance(),
-		StateDatabaseConnection:            blocksync.GetStateDbInstance(),
-		TxnDatabaseConnection:              blocksync.GetTxDbInstance(),
-		PodsDatabaseConnection:             blocksync.GetBatchesDbInstance(),
-		DataAvailabilityDatabaseConnection: blocksync.GetDaDbInstance(),
-		StaticDatabaseConnection:           blocksync.GetStaticDbInstance(),
-		MockDatabaseConnection:             blocksync.GetMockDbInstance(),
-		PublicWitnessConnection:            blocksync.GetPublicWitnessDbInstance(),
+// ViewPodState calls view with the pod state while holding the pod state
+// lock, so it sees no update half done.
+func ViewPodState(view func(podState *PodState)) {
+	mu.Lock()
+	defer mu.Unlock()
+	if Node.podState != nil {
+		view(Node.podState)
 	}
 }
 
-func (c *Connections) GetBlockDatabaseConnection() *leveldb.DB {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.BlockDatabaseConnection
-}
-
-func (c *Connections) GetTxnDatabaseConnection() *leveldb.DB {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.TxnDatabaseConnection
-}
-
-func (c *Connections) GetPodsDatabaseConnection() *leveldb.DB {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.PodsDatabaseConnection
-}
-
-func (c *Connections) GetDataAvailabilityDatabaseConnection() *leveldb.DB {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.DataAvailabilityDatabaseConnection
-}
-
-func (c *Connections) GetStaticDatabaseConnection() *leveldb.DB {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.StaticDatabaseConnection
-}
-
-func (c *Connections) GetStateDatabaseConnection() *leveldb.DB {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.StateDatabaseConnection
-}
-
-func (c *Connections) GetPublicWitnessDbInstance() *leveldb.DB {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.PublicWitnessConnection
+// UpdatePodState applies update to the pod state while holding the pod
+// state lock, so concurrent updates of different fields are not lost.
+func UpdatePodState(update func(podState *PodState)) {
+	mu.Lock()
+	defer mu.Unlock()
+	if Node.podState != nil {
+		update(Node.podState)
+	}
 }
 
-func CheckAndInitializeDBCounters(staticDB *leveldb.DB) {
+func CheckAndInitializeDBCounters(staticDB store.Namespace) {
 	ensureCounter(staticDB, "batchStartIndex")
 	ensureCounter(staticDB, "batchCount")
 }
 
-func ensureCounter(db *leveldb.DB, counterKey string) {
-	//fmt.Println(db)
-	if _, err := db.Get([]byte(counterKey), nil); err != nil {
-		if err = db.Put([]byte(counterKey), []byte("0"), nil); err != nil {
+func ensureCounter(db store.Namespace, counterKey string) {
+	if _, err := db.Get([]byte(counterKey)); err != nil {
+		if err = db.Put([]byte(counterKey), []byte("0")); err != nil {
 			logs.Log.Error(fmt.Sprintf("Error in saving %s in static db: %s", counterKey, err.Error()))
 			return
 		}
 	}
 }
 
-func GetLatestBlock(blockDB *leveldb.DB) int {
-	latestBlockBytes, err := blockDB.Get([]byte("blockCount"), nil)
+func GetLatestBlock(blockDB store.Namespace) int {
+	latestBlockBytes, err := blockDB.Get([]byte("blockCount"))
 	if err != nil {
 		logs.Log.Error(fmt.Sprintf("Error in getting blockCount from block db: %s", err.Error()))
 		return 0
@@ -182,52 +145,12 @@ func GetLatestBlock(blockDB *leveldb.DB) int {
 
 func NewNode(conf *config.Config) {
 
-	NodeConnections := InitializeDatabaseConnections()
-	stateConnection := NodeConnections.GetStateDatabaseConnection()
-	podState := InitializePodState(stateConnection)
+	db := blocksync.GetStore()
+	podState := InitializePodState(db.PodState())
 
 	Node = &NodeS{
-		Config:          conf,
-		podState:        podState,
-		NodeConnections: NodeConnections,
-	}
-}
-
-func LoadConfig() (cnf *config.Config, err error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("%v", err) // Return error, perhaps log it as well
-	}
-	configDir := filepath.Join(homeDir, config.DefaultTracksDir, config.DefaultConfigDir)
-
-	_, err = os.Stat(configDir)
-	if os.IsNotExist(err) {
-		return nil, fmt.Errorf("config directory not found: %s", configDir)
-	}
-	//
-	//viper.AddConfigPath(configDir)
-	//viper.SetConfigName("sequencer")
-	//viper.SetConfigType("toml")
-	//
-	//if err = viper.ReadInConfig(); err != nil {
-	//	return nil, err
-	//}
-	//
-	//if err = viper.Unmarshal(&config); err != nil {
-	//	return nil, err
-	//}
-	//
-	//fmt.Println(config)
-	ConfigFilePath := filepath.Join(configDir, config.DefaultConfigFileName)
-	bytes, err := os.ReadFile(ConfigFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("error in reading config file: %s : %v", ConfigFilePath, err)
-	}
-
-	var conf config.Config // JunctionConfig
-	if err = toml.Unmarshal(bytes, &conf); err != nil {
-		return nil, fmt.Errorf("error unmarshalling config: %v", err)
+		Config:   conf,
+		podState: podState,
+		Store:    db,
 	}
-
-	return &conf, nil
 }
@@ -0,0 +1,131 @@
+package p2p
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/libp2p/go-libp2p/core/control"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	multiaddr "github.com/multiformats/go-multiaddr"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const banListFileName = "ban_list.json"
+
+// BannedPeer is a peer that is refused until a given time.
+type BannedPeer struct {
+	ID     peer.ID   `json:"id"`
+	Until  time.Time `json:"until"`
+	Reason string    `json:"reason"`
+}
+
+// banList holds the banned peers, saved to a file so bans outlive restarts.
+// It is the connection gater of the host: banned peers are neither dialed
+// nor accepted.
+type banList struct {
+	path string
+	mu   sync.Mutex
+	bans map[peer.ID]BannedPeer
+}
+
+// BanListPath is where the ban list of this node is kept.
+func BanListPath() string {
+	return filepath.Join(config.HomeDir(), config.DefaultDataDir, banListFileName)
+}
+
+// loadBanList reads the ban list at path, which may not exist yet. Expired
+// bans are dropped.
+func loadBanList(path string) (*banList, error) {
+	b := &banList{path: path, bans: make(map[peer.ID]BannedPeer)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading ban list: %v", err)
+	}
+	var bans []BannedPeer
+	if err = json.Unmarshal(data, &bans); err != nil {
+		return nil, fmt.Errorf("error decoding ban list %s: %v", path, err)
+	}
+	now := time.Now()
+	for _, ban := range bans {
+		if ban.Until.After(now) {
+			b.bans[ban.ID] = ban
+		}
+	}
+	return b, nil
+}
+
+// add bans id for d and saves the list.
+func (b *banList) add(id peer.ID, d time.Duration, reason string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bans[id] = BannedPeer{ID: id, Until: time.Now().Add(d), Reason: reason}
+	return b.save()
+}
+
+func (b *banList) save() error {
+	data, err := json.MarshalIndent(b.listLocked(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(b.path, data, 0644)
+}
+
+func (b *banList) isBanned(id peer.ID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ban, ok := b.bans[id]
+	return ok && time.Now().Before(ban.Until)
+}
+
+// list returns the bans in force, the one expiring first first.
+func (b *banList) list() []BannedPeer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.listLocked()
+}
+
+func (b *banList) listLocked() []BannedPeer {
+	now := time.Now()
+	bans := []BannedPeer{}
+	for _, ban := range b.bans {
+		if ban.Until.After(now) {
+			bans = append(bans, ban)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].Until.Before(bans[j].Until)
+	})
+	return bans
+}
+
+func (b *banList) InterceptPeerDial(id peer.ID) bool {
+	return !b.isBanned(id)
+}
+
+func (b *banList) InterceptAddrDial(id peer.ID, _ multiaddr.Multiaddr) bool {
+	return !b.isBanned(id)
+}
+
+func (b *banList) InterceptAccept(network.ConnMultiaddrs) bool {
+	return true
+}
+
+// InterceptSecured refuses inbound connections once the peer ID is known.
+func (b *banList) InterceptSecured(_ network.Direction, id peer.ID, _ network.ConnMultiaddrs) bool {
+	return !b.isBanned(id)
+}
+
+func (b *banList) InterceptUpgraded(network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
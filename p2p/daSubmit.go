@@ -0,0 +1,90 @@
+package p2p
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/airchains-network/decentralized-sequencer/da"
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/airchains-network/decentralized-sequencer/node/shared"
+	"github.com/airchains-network/decentralized-sequencer/types"
+	"github.com/rs/zerolog/log"
+)
+
+const daRetryDuration = 10 * time.Second
+
+// podDAData encodes the current pod state into the blob posted to the DA layer.
+func podDAData(compress bool) ([]byte, error) {
+	podState := shared.GetPodState()
+	return da.EncodePodBlob(&da.PodBlob{
+		PodNumber:       podState.LatestPodHeight,
+		PreviousPodHash: podState.PreviousPodHash,
+		PodHash:         podState.LatestPodHash,
+		TracksAppHash:   podState.TracksAppHash,
+		Proof:           podState.LatestPodProof,
+		PublicWitness:   podState.LatestPublicWitness,
+		Batch:           podState.Batch,
+	}, compress)
+}
+
+// submitPodToDA posts the current pod to the DA backend configured in
+// sequencer.toml and saves the returned pointer as da-<podNumber> in the DA
+// database. When retry is set, a failed submission is retried until the DA
+// layer accepts it.
+func submitPodToDA(podNumber int, retry bool) error {
+	baseConfig, err := config.Current()
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	client, err := da.NewClient(baseConfig.DA)
+	if err != nil {
+		return err
+	}
+
+	daData, err := podDAData(baseConfig.DA.DaCompression)
+	if err != nil {
+		return fmt.Errorf("error encoding pod blob: %w", err)
+	}
+
+	var daKey string
+	for {
+		daKey, err = client.Submit(daData, podNumber)
+		if err == nil {
+			break
+		}
+		if !retry {
+			return fmt.Errorf("error in submitting data to DA: %w", err)
+		}
+		logs.Log.Debug("Error in submitting data to DA " + err.Error())
+		logs.Log.Debug(fmt.Sprintf("Retrying %s after %s", client.Name(), daRetryDuration))
+		time.Sleep(daRetryDuration)
+	}
+
+	daPointer := types.DAStruct{
+		DAKey:             daKey,
+		DAClientName:      client.Name(),
+		BatchNumber:       strconv.Itoa(podNumber),
+		PreviousStateHash: string(shared.GetPodState().PreviousPodHash),
+		CurrentStateHash:  string(shared.GetPodState().TracksAppHash),
+		Commitment:        da.Commitment(daData),
+		DataLength:        len(daData),
+	}
+
+	daStoreKey := fmt.Sprintf("da-%d", podNumber)
+	daStoreData, err := json.Marshal(daPointer)
+	if err != nil {
+		return fmt.Errorf("error in marshaling DA pointer : %w", err)
+	}
+
+	daDB := shared.Node.Store.DA()
+	if err = daDB.Put([]byte(daStoreKey), daStoreData); err != nil {
+		return fmt.Errorf("error in saving DA pointer in pod database : %w", err)
+	}
+
+	log.Info().Str("module", "p2p").Str("daClient", client.Name()).Msg("Data Saved in DA")
+	return nil
+}
@@ -0,0 +1,201 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
+	drouting "github.com/libp2p/go-libp2p/p2p/discovery/routing"
+	dutil "github.com/libp2p/go-libp2p/p2p/discovery/util"
+	"sync"
+	"time"
+)
+
+const (
+	// dhtProtocolPrefix keeps the tracks DHT apart from the public IPFS one
+	dhtProtocolPrefix = "/station/tracks"
+	discoveryInterval = time.Minute
+	rejectedPeerTTL   = 10 * time.Minute
+	admitTimeout      = 30 * time.Second
+)
+
+// discovery finds other tracks of the station with mDNS and the DHT and
+// admits those that prove, through the handshake, that their junction
+// address is in the Tracks list of the station.
+type discovery struct {
+	ctx      context.Context
+	host     host.Host
+	identity *trackIdentity
+
+	mu       sync.Mutex
+	pending  map[peer.ID]bool
+	rejected map[peer.ID]time.Time
+}
+
+// startDiscovery starts the discovery enabled in the [p2p] section.
+func startDiscovery(ctx context.Context, node host.Host, p2pConfig *config.P2PConfig) error {
+	if !p2pConfig.MDNS && !p2pConfig.DHT {
+		return nil
+	}
+	identity, err := newTrackIdentity()
+	if err != nil {
+		return fmt.Errorf("peer discovery: %v", err)
+	}
+	stationId := identity.stationId
+
+	d := &discovery{
+		ctx:      ctx,
+		host:     node,
+		identity: identity,
+		pending:  make(map[peer.ID]bool),
+		rejected: make(map[peer.ID]time.Time),
+	}
+	node.SetStreamHandler(protocol.ID(handshakeProtocolID), d.handleHandshake)
+
+	if p2pConfig.MDNS {
+		service := mdns.NewMdnsService(node, "tracks-"+stationId, d)
+		if err = service.Start(); err != nil {
+			return fmt.Errorf("error starting mDNS: %v", err)
+		}
+		go func() {
+			<-ctx.Done()
+			service.Close()
+		}()
+		logs.Log.Info("mDNS peer discovery started")
+	}
+	if p2pConfig.DHT {
+		if err = d.startDHT(p2pConfig.BootstrapPeers, "tracks/"+stationId); err != nil {
+			return err
+		}
+		logs.Log.Info("DHT peer discovery started")
+	}
+	return nil
+}
+
+// HandlePeerFound is called by mDNS for every track found on the network.
+func (d *discovery) HandlePeerFound(info peer.AddrInfo) {
+	go d.admit(info)
+}
+
+func (d *discovery) startDHT(bootstrapPeers []string, rendezvous string) error {
+	var seeds []peer.AddrInfo
+	for _, addr := range bootstrapPeers {
+		info, err := peer.AddrInfoFromString(addr)
+		if err != nil {
+			logs.Log.Warn(fmt.Sprintf("Ignoring bootstrap peer %q: %v", addr, err))
+			continue
+		}
+		if info.ID != d.host.ID() {
+			seeds = append(seeds, *info)
+		}
+	}
+
+	kdht, err := dht.New(d.ctx, d.host,
+		dht.Mode(dht.ModeAutoServer),
+		dht.ProtocolPrefix(protocol.ID(dhtProtocolPrefix)),
+		dht.BootstrapPeers(seeds...),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating DHT: %v", err)
+	}
+	if err = kdht.Bootstrap(d.ctx); err != nil {
+		return fmt.Errorf("error bootstrapping DHT: %v", err)
+	}
+	for _, seed := range seeds {
+		go d.admit(seed)
+	}
+
+	routing := drouting.NewRoutingDiscovery(kdht)
+	dutil.Advertise(d.ctx, routing, rendezvous)
+	go func() {
+		defer kdht.Close()
+		ticker := time.NewTicker(discoveryInterval)
+		defer ticker.Stop()
+		for {
+			found, err := routing.FindPeers(d.ctx, rendezvous)
+			if err != nil {
+				logs.Log.Warn(fmt.Sprintf("Error finding tracks in the DHT: %v", err))
+			} else {
+				for info := range found {
+					if len(info.Addrs) > 0 {
+						go d.admit(info)
+					}
+				}
+			}
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// admit connects to a discovered peer and runs the handshake with it.
+func (d *discovery) admit(info peer.AddrInfo) {
+	if info.ID == d.host.ID() || manager.isPersistent(info.ID) {
+		return
+	}
+	d.mu.Lock()
+	if d.pending[info.ID] || time.Now().Before(d.rejected[info.ID]) {
+		d.mu.Unlock()
+		return
+	}
+	d.pending[info.ID] = true
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		delete(d.pending, info.ID)
+		d.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(d.ctx, admitTimeout)
+	defer cancel()
+	if err := d.host.Connect(ctx, info); err != nil {
+		logs.Log.Debug(fmt.Sprintf("Unable to connect to discovered peer %s: %v", info.ID, err))
+		return
+	}
+	address, err := d.identity.requestHandshake(ctx, d.host, info.ID)
+	if err != nil {
+		d.reject(info.ID, err)
+		return
+	}
+	d.accept(info, address)
+}
+
+func (d *discovery) handleHandshake(s network.Stream) {
+	defer s.Close()
+	remote := s.Conn().RemotePeer()
+	address, err := d.identity.handshake(s, false)
+	if err != nil {
+		_ = s.Reset()
+		d.reject(remote, err)
+		return
+	}
+	// the inbound address has an ephemeral port, the peer manager dials the
+	// listen addresses learned through identify instead
+	d.accept(peer.AddrInfo{ID: remote}, address)
+}
+
+func (d *discovery) accept(info peer.AddrInfo, address string) {
+	if manager.isPersistent(info.ID) {
+		return
+	}
+	logs.Log.Info(fmt.Sprintf("Admitted track %s as peer %s", address, info.ID))
+	manager.addPeer(info)
+}
+
+func (d *discovery) reject(id peer.ID, err error) {
+	logs.Log.Warn(fmt.Sprintf("Rejected peer %s: %v", id, err))
+	d.mu.Lock()
+	d.rejected[id] = time.Now().Add(rejectedPeerTTL)
+	d.mu.Unlock()
+	_ = d.host.Network().ClosePeer(id)
+}
@@ -0,0 +1,145 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// gossipTopic is the GossipSub topic the tracks of one station publish the
+// vrfInitiated, vrnValidated, podSubmitted and podVerified messages on. Its
+// messages are frames of the wire protocol.
+type gossipTopic struct {
+	host  host.Host
+	auth  *messageAuth
+	topic *pubsub.Topic
+}
+
+// openedMessage is the authenticated data of a gossip message, handed from
+// validate to readLoop.
+type openedMessage struct {
+	sender string
+	data   []byte
+}
+
+var gossip *gossipTopic
+
+func gossipTopicName(stationId string) string {
+	return "/station/tracks/" + stationId + "/gossip/1.0.0"
+}
+
+// gossipMessageID identifies a message by its content, so a message
+// published twice, or received through several tracks, is processed once.
+func gossipMessageID(msg *pb.Message) string {
+	sum := sha256.Sum256(msg.Data)
+	return hex.EncodeToString(sum[:])
+}
+
+// startGossip joins the topic of the station and processes its messages
+// until ctx is done.
+func startGossip(ctx context.Context, node host.Host) error {
+	if authenticator == nil {
+		return fmt.Errorf("gossip needs the junction account to sign messages")
+	}
+	g, err := joinGossip(ctx, node, authenticator, func(dataType string, data []byte, from peer.ID, sender string) {
+		ProcessGossipMessage(Node, dataType, data, from, sender)
+	})
+	if err != nil {
+		return err
+	}
+	gossip = g
+	return nil
+}
+
+func joinGossip(ctx context.Context, node host.Host, auth *messageAuth, deliver func(dataType string, data []byte, from peer.ID, sender string)) (*gossipTopic, error) {
+	ps, err := pubsub.NewGossipSub(ctx, node,
+		pubsub.WithMessageIdFn(gossipMessageID),
+		pubsub.WithMaxMessageSize(frameHeaderSize+MaxMessageSize+4096),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gossipsub: %v", err)
+	}
+	g := &gossipTopic{host: node, auth: auth}
+	name := gossipTopicName(auth.identity.stationId)
+	if err = ps.RegisterTopicValidator(name, g.validate); err != nil {
+		return nil, fmt.Errorf("error registering gossip validator: %v", err)
+	}
+	if g.topic, err = ps.Join(name); err != nil {
+		return nil, fmt.Errorf("error joining %s: %v", name, err)
+	}
+	sub, err := g.topic.Subscribe()
+	if err != nil {
+		return nil, fmt.Errorf("error subscribing to %s: %v", name, err)
+	}
+	go g.readLoop(ctx, sub, deliver)
+	return g, nil
+}
+
+func (g *gossipTopic) readLoop(ctx context.Context, sub *pubsub.Subscription, deliver func(dataType string, data []byte, from peer.ID, sender string)) {
+	defer sub.Cancel()
+	for {
+		msg, err := sub.Next(ctx)
+		if err != nil {
+			return
+		}
+		// our own messages are delivered to us too
+		if msg.GetFrom() == g.host.ID() {
+			continue
+		}
+		dataType, _, err := readFrame(bytes.NewReader(msg.Data))
+		opened, ok := msg.ValidatorData.(*openedMessage)
+		if err != nil || !ok {
+			continue
+		}
+		go deliver(dataType, opened.data, msg.GetFrom(), opened.sender)
+	}
+}
+
+// validate runs before a message is delivered or relayed. Messages relayed
+// by peers that are not admitted tracks, messages seen before and messages
+// over the rate limit of their publisher are ignored; messages that are not
+// signed by a track of the station are rejected and cost the relay points.
+func (g *gossipTopic) validate(_ context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+	if from == g.host.ID() {
+		return pubsub.ValidationAccept
+	}
+	if manager == nil || !manager.isPersistent(from) {
+		return pubsub.ValidationIgnore
+	}
+	dataType, data, err := readFrame(bytes.NewReader(msg.Data))
+	if err != nil {
+		scores.penalize(from, penaltyDecodeError, fmt.Errorf("invalid gossip: %v", err))
+		return pubsub.ValidationReject
+	}
+	if !scores.allow(msg.GetFrom(), dataType) {
+		return pubsub.ValidationIgnore
+	}
+	sender, data, err := g.auth.open(dataType, data)
+	if err == errReplayedMessage {
+		return pubsub.ValidationIgnore
+	}
+	if err != nil {
+		// tracks only relay messages they validated, so the relay is to blame
+		scores.penalize(from, penaltyFor(err), fmt.Errorf("%s gossip from %s: %v", dataType, msg.GetFrom(), err))
+		return pubsub.ValidationReject
+	}
+	scores.reward(from)
+	msg.ValidatorData = &openedMessage{sender: sender, data: data}
+	return pubsub.ValidationAccept
+}
+
+// publish sends a message, signed by messageAuth.sign, to every track of
+// the station.
+func (g *gossipTopic) publish(ctx context.Context, dataType string, data []byte) error {
+	frame, err := encodeFrame(dataType, data)
+	if err != nil {
+		return err
+	}
+	return g.topic.Publish(ctx, frame)
+}
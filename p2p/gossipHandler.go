@@ -2,11 +2,8 @@ package p2p
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/airchains-network/decentralized-sequencer/da/avail"
-	"github.com/airchains-network/decentralized-sequencer/da/celestia"
-	"github.com/airchains-network/decentralized-sequencer/da/eigen"
-	mock "github.com/airchains-network/decentralized-sequencer/da/mockda"
 	"github.com/airchains-network/decentralized-sequencer/junction"
 	junctionTypes "github.com/airchains-network/decentralized-sequencer/junction/types"
 	logs "github.com/airchains-network/decentralized-sequencer/log"
@@ -18,7 +15,6 @@ import (
 	"github.com/rs/zerolog/log"
 	"math/rand"
 	"os"
-	"strconv"
 	"time"
 )
 
@@ -41,8 +37,14 @@ const (
 	LogPodVerifyFailed      = "Pod verification failed"
 	LogMarshalPodVerified   = "Error in Marshaling PodVerifiedMsg"
 	LogMarshalGossipMsg     = "Error marshaling gossip message"
+
+	// podWaitTimeout bounds how long a message of the next pod waits for
+	// this track to reach it.
+	podWaitTimeout = 10 * time.Minute
 )
 
+var errPodWaitTimeout = errors.New("timed out waiting for pod")
+
 type PodSubmittedMessageHandler struct {
 	message PodSubmittedMsgData
 }
@@ -75,7 +77,8 @@ type AccountDetails struct {
 // - dataType: The type of gossip data being processed
 // - dataByte: The byte slice representation of the gossip data
 // - messageBroadcaster: The ID of the peer who broadcasted the message
-func ProcessGossipMessage(node host.Host, dataType string, dataByte []byte, messageBroadcaster peer.ID) {
+// - sender: The junction address of the track that signed the message
+func ProcessGossipMessage(node host.Host, dataType string, dataByte []byte, messageBroadcaster peer.ID, sender string) {
 	messageHandlers := map[string]func([]byte){
 		"vrfInitiated": func(dataByte []byte) {
 			handler := NewVRFInitiatedMessageHandler(dataByte)
@@ -96,13 +99,42 @@ func ProcessGossipMessage(node host.Host, dataType string, dataByte []byte, mess
 				handler.HandlePodMessage()
 			}
 		},
+		"podVote": func(dataByte []byte) {
+			PodVoteMsgHandler(dataByte, messageBroadcaster)
+		},
+		"podVoteRequest": func(dataByte []byte) {
+			PodVoteRequestMsgHandler(dataByte, messageBroadcaster)
+		},
+		"vrnVote": func(dataByte []byte) {
+			VrnVoteMsgHandler(dataByte, messageBroadcaster)
+		},
 	}
 
-	if handler, found := messageHandlers[dataType]; found {
-		handler(dataByte)
-	} else {
+	handler, found := messageHandlers[dataType]
+	if !found {
 		logs.Log.Error("Unknown gossip data type found")
+		return
 	}
+	podNumber, err := podNumberOf(dataByte)
+	if err != nil {
+		scores.penalize(messageBroadcaster, penaltyDecodeError, err)
+		return
+	}
+	if err = waitUntilPodNumberMatched(podNumber); err != nil {
+		logs.Log.Warn(fmt.Sprintf("Dropped %s from %s: %v", dataType, messageBroadcaster, err))
+		if errors.Is(err, errPodWaitTimeout) {
+			scores.penalize(messageBroadcaster, penaltyTimeout, err)
+		}
+		return
+	}
+	if err = checkStepSender(dataType, sender); err != nil {
+		logs.Log.Warn(fmt.Sprintf("Dropped %s from %s: %v", dataType, messageBroadcaster, err))
+		scores.penalize(messageBroadcaster, penaltyWrongSender, err)
+		return
+	}
+	recordStepSender(podNumber, dataType, sender, dataByte)
+	observePodStep(podNumber, dataType)
+	handler(dataByte)
 }
 
 // NewVRFInitiatedMessageHandler takes in a byte slice representing the VRFInitiated message,
@@ -123,7 +155,10 @@ func NewVRFInitiatedMessageHandler(dataByte []byte) *VRFInitiatedMessageHandler
 }
 
 func (h *VRFInitiatedMessageHandler) HandleVRFInitiatedMessage() {
-	waitUntilPodNumberMatched(h.message.PodNumber)
+	if err := waitUntilPodNumberMatched(h.message.PodNumber); err != nil {
+		logs.Log.Warn(err.Error())
+		return
+	}
 
 	accountDetails, err := getAccountDetails()
 	if err != nil {
@@ -132,10 +167,9 @@ func (h *VRFInitiatedMessageHandler) HandleVRFInitiatedMessage() {
 	}
 
 	// all nodes: update vrn init hash
-	currentPodState := shared.GetPodState()
-	VrfInitTxHash := h.message.VrfInitTxHash
-	currentPodState.VRFInitiationTxHash = VrfInitTxHash
-	shared.SetPodState(currentPodState)
+	shared.UpdatePodState(func(podState *shared.PodState) {
+		podState.VRFInitiationTxHash = h.message.VrfInitTxHash
+	})
 
 	// selected node
 	if h.message.SelectedTrackAddress == accountDetails.MyAddress {
@@ -152,15 +186,23 @@ func decodeVRFInitiatedMsg(dataByte []byte) *VRFInitiatedMsgData {
 	return &VRFInitiatedMsg
 }
 
-func waitUntilPodNumberMatched(podNumber uint64) {
+// waitUntilPodNumberMatched waits, up to podWaitTimeout, for this track to
+// reach the pod podNumber.
+func waitUntilPodNumberMatched(podNumber uint64) error {
+	deadline := time.Now().Add(podWaitTimeout)
 	for {
 		podState := shared.GetPodState()
 		if podNumber == podState.LatestPodHeight {
-			logs.Log.Info("Pod number matched")
-			break
+			return nil
+		}
+		if podNumber < podState.LatestPodHeight {
+			return fmt.Errorf("pod %d is done, this track is at pod %d", podNumber, podState.LatestPodHeight)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w %d, this track is at pod %d", errPodWaitTimeout, podNumber, podState.LatestPodHeight)
 		}
-		logs.Log.Warn("pod number is not matched, waiting for new pod")
-		time.Sleep(3 * time.Second)
+		logs.Log.Warn(LogPodMismatch)
+		time.Sleep(SleepDuration)
 	}
 }
 
@@ -201,10 +243,18 @@ func processVerifiedVRF(VRFInitiatedMsg *VRFInitiatedMsgData, ad *AccountDetails
 		return
 	}
 	if !vrfRecord.IsVerified {
-		logs.Log.Error("Verification of VRF is failed, need Voting for correct VRN")
+		logs.Log.Warn("Verification of VRF is failed, voting on the VRN")
+		if _, err := disputeVRF(vrfRecord); err != nil {
+			logs.Log.Error(err.Error())
+		}
 		return
 	}
+	announceValidatedVRN(vrfRecord, ad)
+}
 
+// announceValidatedVRN tells the tracks which of them the VRN of the pod
+// selected to submit it.
+func announceValidatedVRN(vrfRecord *junctionTypes.VrfRecord, ad *AccountDetails) {
 	PodNumber := int(shared.GetPodState().LatestPodHeight)
 	SelectedTrackAddress := ad.Tracks[vrfRecord.SelectedTrackIndex]
 	VrnValidatedTxHash := shared.GetPodState().VRFValidationTxHash
@@ -245,21 +295,15 @@ func VRNValidatedMsgHandler(dataByte []byte) {
 		return
 	}
 
-	for {
-		podState := shared.GetPodState()
-		if VRNVerifiedMsg.PodNumber == podState.LatestPodHeight {
-			break
-		}
-		logs.Log.Warn("pod number is not matched, waiting for new pod")
-		fmt.Println(VRNVerifiedMsg.PodNumber, podState.LatestPodHeight)
-		time.Sleep(3 * time.Second)
+	if err := waitUntilPodNumberMatched(VRNVerifiedMsg.PodNumber); err != nil {
+		logs.Log.Warn(err.Error())
+		return
 	}
 
 	// all nodes: update txHash of vrn validated
-	VRFValidationTxHash := VRNVerifiedMsg.VRFVerifiedTxHash
-	currentPodState := shared.GetPodState()
-	currentPodState.VRFValidationTxHash = VRFValidationTxHash
-	shared.SetPodState(currentPodState)
+	shared.UpdatePodState(func(podState *shared.PodState) {
+		podState.VRFValidationTxHash = VRNVerifiedMsg.VRFVerifiedTxHash
+	})
 
 	// check if this node is selected to submit pod & da
 	_, _, accountPath, accountName, addressPrefix, tracks, err := junction.GetJunctionDetails()
@@ -275,140 +319,9 @@ func VRNValidatedMsgHandler(dataByte []byte) {
 	// now check for this pod number, who is the selected track
 	if VRNVerifiedMsg.SelectedTrackAddress == myAddress {
 		// submit data to DA
-		DaData := shared.GetPodState().Batch.TransactionHash
-		daDataByte := []byte{}
-		for _, str := range DaData {
-			daDataByte = append(daDataByte, []byte(str)...)
-		}
-
 		PodNumber := int(shared.GetPodState().LatestPodHeight)
-		connection := shared.Node.NodeConnections
-		DaBatchSaver := connection.DataAvailabilityDatabaseConnection
-
-		baseConfig, err := shared.LoadConfig()
-		if err != nil {
-			fmt.Println("Error loading configuration")
-		}
-		Datype := baseConfig.DA.DaType
-		if Datype == "mock" {
-			mdb := connection.MockDatabaseConnection
-			daCheck, daCheckErr := mock.MockDA(mdb, daDataByte, PodNumber)
-			if daCheckErr != nil {
-				logs.Log.Error("Error in submitting data to DA")
-				return
-			}
-
-			da := types.DAStruct{
-				DAKey:             daCheck,
-				DAClientName:      "mock-da",
-				BatchNumber:       strconv.Itoa(PodNumber),
-				PreviousStateHash: string(shared.GetPodState().PreviousPodHash),
-				CurrentStateHash:  string(shared.GetPodState().TracksAppHash),
-			}
-
-			daStoreKey := fmt.Sprintf("da-%d", PodNumber)
-			daStoreData, daStoreDataErr := json.Marshal(da)
-			if daStoreDataErr != nil {
-				logs.Log.Warn(fmt.Sprintf("Error in marshaling DA pointer : %s", daStoreDataErr.Error()))
-			}
-
-			storeErr := DaBatchSaver.Put([]byte(daStoreKey), daStoreData, nil)
-			if storeErr != nil {
-				logs.Log.Warn(fmt.Sprintf("Error in saving DA pointer in pod database : %s", storeErr.Error()))
-			}
-
-			log.Info().Str("module", "p2p").Msg("Data Saved in DA")
-
-		} else if Datype == "avail" {
-			daCheck, daCheckErr := avail.Avail(daDataByte, baseConfig.DA.DaRPC)
-			if daCheckErr != nil {
-				logs.Log.Warn("Error in submitting data to DA")
-				return
-			}
-
-			da := types.DAStruct{
-				DAKey:             daCheck,
-				DAClientName:      "avail-da",
-				BatchNumber:       strconv.Itoa(PodNumber),
-				PreviousStateHash: string(shared.GetPodState().PreviousPodHash),
-				CurrentStateHash:  string(shared.GetPodState().TracksAppHash),
-			}
-
-			daStoreKey := fmt.Sprintf("da-%d", PodNumber)
-			daStoreData, daStoreDataErr := json.Marshal(da)
-			if daStoreDataErr != nil {
-				logs.Log.Warn(fmt.Sprintf("Error in marshaling DA pointer : %s", daStoreDataErr.Error()))
-			}
-
-			storeErr := DaBatchSaver.Put([]byte(daStoreKey), daStoreData, nil)
-			if storeErr != nil {
-				logs.Log.Warn(fmt.Sprintf("Error in saving DA pointer in pod database : %s", storeErr.Error()))
-			}
-
-			log.Info().Str("module", "p2p").Msg("Data Saved in DA")
-
-		} else if Datype == "celestia" {
-			daCheck, daCheckErr := celestia.Celestia(daDataByte, baseConfig.DA.DaRPC, baseConfig.DA.DaRPC)
-
-			if daCheckErr != nil {
-				logs.Log.Warn("Error in submitting data to DA")
-				return
-			}
-
-			da := types.DAStruct{
-				DAKey:             daCheck,
-				DAClientName:      "celestia-da",
-				BatchNumber:       strconv.Itoa(PodNumber),
-				PreviousStateHash: string(shared.GetPodState().PreviousPodHash),
-				CurrentStateHash:  string(shared.GetPodState().TracksAppHash),
-			}
-
-			daStoreKey := fmt.Sprintf("da-%d", PodNumber)
-			daStoreData, daStoreDataErr := json.Marshal(da)
-			if daStoreDataErr != nil {
-				logs.Log.Warn(fmt.Sprintf("Error in marshaling DA pointer : %s", daStoreDataErr.Error()))
-			}
-
-			storeErr := DaBatchSaver.Put([]byte(daStoreKey), daStoreData, nil)
-			if storeErr != nil {
-				logs.Log.Warn(fmt.Sprintf("Error in saving DA pointer in pod database : %s", storeErr.Error()))
-			}
-
-			log.Info().Str("module", "p2p").Msg("Data Saved in DA")
-
-		} else if Datype == "eigen" {
-			daCheck, daCheckErr := eigen.Eigen(daDataByte,
-				baseConfig.DA.DaRPC, baseConfig.DA.DaRPC,
-			)
-
-			if daCheckErr != nil {
-				logs.Log.Warn("Error in submitting data to DA")
-				return
-			}
-
-			da := types.DAStruct{
-				DAKey:             daCheck,
-				DAClientName:      "eigen-da",
-				BatchNumber:       strconv.Itoa(PodNumber),
-				PreviousStateHash: string(shared.GetPodState().PreviousPodHash),
-				CurrentStateHash:  string(shared.GetPodState().TracksAppHash),
-			}
-
-			daStoreKey := fmt.Sprintf("da-%d", PodNumber)
-			daStoreData, daStoreDataErr := json.Marshal(da)
-			if daStoreDataErr != nil {
-				logs.Log.Warn(fmt.Sprintf("Error in marshaling DA pointer : %s", daStoreDataErr.Error()))
-			}
-
-			storeErr := DaBatchSaver.Put([]byte(daStoreKey), daStoreData, nil)
-			if storeErr != nil {
-				logs.Log.Warn(fmt.Sprintf("Error in saving DA pointer in pod database : %s", storeErr.Error()))
-			}
-
-			log.Info().Str("module", "p2p").Msg("Data Saved in DA")
-
-		} else {
-			logs.Log.Error("Unknown layer. Please use 'avail' or 'celestia' as argument.")
+		if err := submitPodToDA(PodNumber, false); err != nil {
+			logs.Log.Warn(err.Error())
 			return
 		}
 
@@ -468,22 +381,13 @@ func NewPodSubmittedMessageHandler(dataByte []byte) *PodSubmittedMessageHandler
 }
 
 func (h *PodSubmittedMessageHandler) HandlePodSubmissionMessage() {
-	for {
-		podState := shared.GetPodState()
-		if h.message.PodNumber == podState.LatestPodHeight {
-			break
-		}
-		h.logAndSleep(LogPodSubmitMatchFail, h.message.PodNumber, podState.LatestPodHeight)
+	if err := waitUntilPodNumberMatched(h.message.PodNumber); err != nil {
+		logs.Log.Warn(err.Error())
+		return
 	}
 	h.processPodSubmission()
 }
 
-func (h *PodSubmittedMessageHandler) logAndSleep(message string, number, height uint64) {
-	logs.Log.Warn(message)
-	fmt.Println(number, height)
-	time.Sleep(SleepDuration)
-}
-
 func (h *PodSubmittedMessageHandler) processPodSubmission() {
 	_, _, accountPath, accountName, addressPrefix, _, err := junction.GetJunctionDetails()
 	if err != nil {
@@ -497,10 +401,9 @@ func (h *PodSubmittedMessageHandler) processPodSubmission() {
 	}
 
 	// all nodes: update initPodTxHash
-	currentPodState := shared.GetPodState()
-	InitPodTxHash := h.message.InitPodTxHash
-	currentPodState.InitPodTxHash = InitPodTxHash
-	shared.SetPodState(currentPodState)
+	shared.UpdatePodState(func(podState *shared.PodState) {
+		podState.InitPodTxHash = h.message.InitPodTxHash
+	})
 
 	if h.message.SelectedTrackAddress == myAddress {
 		h.verifyAndBroadcastPod()
@@ -572,34 +475,30 @@ func NewPodVerifiedMessageHandler(dataByte []byte) *PodVerifiedMessageHandler {
 
 func (h *PodVerifiedMessageHandler) HandlePodMessage() {
 	// match the pod number
-	for {
-		podState := shared.GetPodState()
-		if h.message.PodNumber == podState.LatestPodHeight {
-			break
-		}
-		h.logAndSleep(LogPodMismatch, h.message.PodNumber, podState.LatestPodHeight)
+	if err := waitUntilPodNumberMatched(h.message.PodNumber); err != nil {
+		logs.Log.Warn(err.Error())
+		return
 	}
 
 	// update pod verified
-	podState := shared.GetPodState()
-	VerifyPodTxHash := shared.GetPodState().VerifyPodTxHash
-	podState.VerifyPodTxHash = VerifyPodTxHash
-	shared.SetPodState(podState)
+	shared.UpdatePodState(func(podState *shared.PodState) {
+		podState.VerifyPodTxHash = h.message.PodVerifiedTxHash
+	})
 
 	logs.Log.Warn(LogPodMatchSuccess)
 	h.handleVerificationResult()
 }
 
-func (h *PodVerifiedMessageHandler) logAndSleep(message string, number, height uint64) {
-	logs.Log.Warn(message)
-	fmt.Println(number, height)
-	time.Sleep(SleepDuration)
-}
-
 func (h *PodVerifiedMessageHandler) handleVerificationResult() {
 	// update verified hash in all nodes
 
 	if h.message.VerificationResult {
+		// a track saying so is not enough, the junction must have verified it
+		pod := junction.QueryPod(h.message.PodNumber)
+		if pod == nil || !pod.IsVerified {
+			logs.Log.Warn(fmt.Sprintf("Pod %d is not verified on the junction, not saving it", h.message.PodNumber))
+			return
+		}
 		logs.Log.Info(LogPodSave)
 		saveVerifiedPOD() // save the latest pod details and make next pod
 		logs.Log.Info(LogPodGenNext)
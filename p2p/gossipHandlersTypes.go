@@ -19,6 +19,31 @@ type PodSubmittedMsgData struct {
 	InitPodTxHash        string
 }
 
+// PodVoteMsgData is the vote of a track on a pod: the TracksAppHash it
+// derived from its own indexed transactions.
+type PodVoteMsgData struct {
+	PodNumber     uint64
+	Voter         string
+	TracksAppHash []byte
+}
+
+// PodVoteRequestMsgData asks the tracks to send their vote on a pod again.
+type PodVoteRequestMsgData struct {
+	PodNumber uint64
+}
+
+// VrnVoteMsgData is the vote of a track on the VRN of a pod the junction
+// failed to verify: Vote is whether the track checked the VRF proof
+// successfully. Signature is made with the junction key of Voter over
+// junction.VrfDisputeVoteBytes, for MsgProcessVrfDispute.
+type VrnVoteMsgData struct {
+	PodNumber uint64
+	Voter     string
+	Vote      bool
+	PubKey    []byte
+	Signature []byte
+}
+
 type PodVerifiedMsgData struct {
 	PodNumber          uint64
 	VerificationResult bool
@@ -0,0 +1,101 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestGossipDeliversOnceToEveryTrack(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hosts := []host.Host{testHost(t), testHost(t), testHost(t)}
+	manager = &peerManager{peers: make(map[peer.ID]peer.AddrInfo)}
+	defer func() { manager = nil }()
+	for _, h := range hosts {
+		manager.peers[h.ID()] = peer.AddrInfo{ID: h.ID()}
+	}
+
+	type delivery struct {
+		to       peer.ID
+		dataType string
+	}
+	signer := testSigner(t)
+	tracks := []string{signer.Address(), testSigner(t).Address()}
+	auths := make([]*messageAuth, len(hosts))
+	delivered := make(chan delivery, 10)
+	topics := make([]*gossipTopic, len(hosts))
+	for i, h := range hosts {
+		id := h.ID()
+		auths[i] = testAuth(signer, tracks, 1)
+		g, err := joinGossip(ctx, h, auths[i], func(dataType string, _ []byte, _ peer.ID, _ string) {
+			delivered <- delivery{id, dataType}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		topics[i] = g
+	}
+	// a line: hosts[2] only gets the message relayed by hosts[1]
+	for _, pair := range [][2]host.Host{{hosts[0], hosts[1]}, {hosts[1], hosts[2]}} {
+		if err := pair[0].Connect(ctx, peer.AddrInfo{ID: pair[1].ID(), Addrs: pair[1].Addrs()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// publish until both tracks receive, the mesh takes a moment to form
+	warm := map[peer.ID]bool{}
+	for i := 0; len(warm) < 2; i++ {
+		if i == 50 {
+			t.Fatalf("warm up delivered to %v", warm)
+		}
+		signed, err := auths[0].sign("vrnValidated", []byte(fmt.Sprintf(`{"PodNumber":1,"SelectedTrackAddress":%q,"VRFVerifiedTxHash":"%d"}`, tracks[1], i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = topics[0].publish(ctx, "vrnValidated", signed); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(100 * time.Millisecond)
+		for len(delivered) > 0 {
+			warm[(<-delivered).to] = true
+		}
+	}
+	time.Sleep(200 * time.Millisecond)
+	for len(delivered) > 0 {
+		<-delivered
+	}
+
+	signed, err := auths[0].sign("podVerified", []byte(`{"PodNumber":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := topics[0].publish(ctx, "podVerified", signed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := map[peer.ID]int{}
+	timeout := time.After(5 * time.Second)
+	for len(got) < 2 {
+		select {
+		case d := <-delivered:
+			if d.dataType != "podVerified" {
+				t.Fatalf("delivered %s", d.dataType)
+			}
+			got[d.to]++
+		case <-timeout:
+			t.Fatalf("delivered to %v, want the two other tracks", got)
+		}
+	}
+	// give a duplicate the time to show up
+	time.Sleep(500 * time.Millisecond)
+	if len(delivered) > 0 || got[hosts[1].ID()] != 1 || got[hosts[2].ID()] != 1 {
+		t.Fatalf("delivered %v plus %d more, want each message once", got, len(delivered))
+	}
+}
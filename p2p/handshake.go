@@ -0,0 +1,130 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/junction"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"io"
+	"time"
+)
+
+const (
+	handshakeProtocolID = "/station/tracks/handshake/0.0.1"
+	handshakeTimeout    = 10 * time.Second
+	maxHandshakeSize    = 4096
+)
+
+// trackProof binds a libp2p peer ID to a junction track address. It is
+// signed with the junction key of the track over the station ID and the peer
+// ID, so it can not be replayed by another peer or for another station.
+type trackProof struct {
+	StationId string `json:"stationId"`
+	Address   string `json:"address"`
+	PubKey    []byte `json:"pubKey"`
+	Signature []byte `json:"signature"`
+}
+
+// trackIdentity is what a node needs to prove it is a track of the station
+// and to check the proofs of others.
+type trackIdentity struct {
+	signer        *junction.Signer
+	stationId     string
+	addressPrefix string
+	tracks        []string
+}
+
+// newTrackIdentity loads the junction account and the Tracks list of the
+// station.
+func newTrackIdentity() (*trackIdentity, error) {
+	_, stationId, _, _, addressPrefix, tracks, err := junction.GetJunctionDetails()
+	if err != nil {
+		return nil, fmt.Errorf("a created station is needed: %v", err)
+	}
+	signer, err := junction.NewSigner()
+	if err != nil {
+		return nil, fmt.Errorf("the junction account is needed: %v", err)
+	}
+	return &trackIdentity{signer: signer, stationId: stationId, addressPrefix: addressPrefix, tracks: tracks}, nil
+}
+
+func handshakeMessage(stationId string, id peer.ID) []byte {
+	return []byte("tracks-handshake/" + stationId + "/" + id.String())
+}
+
+func (t *trackIdentity) proof(self peer.ID) (*trackProof, error) {
+	pubKey, signature, err := t.signer.Sign(handshakeMessage(t.stationId, self))
+	if err != nil {
+		return nil, err
+	}
+	return &trackProof{StationId: t.stationId, Address: t.signer.Address(), PubKey: pubKey, Signature: signature}, nil
+}
+
+// verify checks that proof was made by a track of the station for remote.
+func (t *trackIdentity) verify(proof *trackProof, remote peer.ID) error {
+	if proof.StationId != t.stationId {
+		return fmt.Errorf("peer %s is a track of station %q", remote, proof.StationId)
+	}
+	if !isTrack(proof.Address, t.tracks) {
+		return fmt.Errorf("%s of peer %s is not a track of the station", proof.Address, remote)
+	}
+	return junction.VerifySignature(proof.Address, t.addressPrefix, proof.PubKey, handshakeMessage(t.stationId, remote), proof.Signature)
+}
+
+func isTrack(address string, tracks []string) bool {
+	for _, track := range tracks {
+		if track == address {
+			return true
+		}
+	}
+	return false
+}
+
+// handshake proves this node to remote and checks the proof of remote. It
+// returns the junction address of remote.
+func (t *trackIdentity) handshake(s network.Stream, initiator bool) (string, error) {
+	_ = s.SetDeadline(time.Now().Add(handshakeTimeout))
+	remote := s.Conn().RemotePeer()
+
+	own, err := t.proof(s.Conn().LocalPeer())
+	if err != nil {
+		return "", err
+	}
+	var theirs trackProof
+	if initiator {
+		if err = json.NewEncoder(s).Encode(own); err != nil {
+			return "", err
+		}
+		if err = json.NewDecoder(io.LimitReader(s, maxHandshakeSize)).Decode(&theirs); err != nil {
+			return "", fmt.Errorf("error reading proof of %s: %v", remote, err)
+		}
+	} else {
+		if err = json.NewDecoder(io.LimitReader(s, maxHandshakeSize)).Decode(&theirs); err != nil {
+			return "", fmt.Errorf("error reading proof of %s: %v", remote, err)
+		}
+		// answer before checking theirs, the initiator verifies us either way
+		if err = json.NewEncoder(s).Encode(own); err != nil {
+			return "", err
+		}
+	}
+	if err = t.verify(&theirs, remote); err != nil {
+		return "", err
+	}
+	return theirs.Address, nil
+}
+
+// requestHandshake opens a handshake stream to a connected peer.
+func (t *trackIdentity) requestHandshake(ctx context.Context, node host.Host, id peer.ID) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+	defer cancel()
+	s, err := node.NewStream(ctx, id, protocol.ID(handshakeProtocolID))
+	if err != nil {
+		return "", err
+	}
+	defer s.Close()
+	return t.handshake(s, true)
+}
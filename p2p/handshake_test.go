@@ -0,0 +1,79 @@
+package p2p
+
+import (
+	"context"
+	"testing"
+
+	"github.com/airchains-network/decentralized-sequencer/junction"
+	"github.com/ignite/cli/v28/ignite/pkg/cosmosaccount"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+func testSigner(t *testing.T) *junction.Signer {
+	t.Helper()
+	registry, err := cosmosaccount.NewInMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = registry.Create("track"); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := junction.NewSignerFromRegistry(registry, "track", "air")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func testHost(t *testing.T) host.Host {
+	t.Helper()
+	h, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func TestHandshakeAdmitsOnlyTracks(t *testing.T) {
+	ctx := context.Background()
+	track, other, outsider := testSigner(t), testSigner(t), testSigner(t)
+	tracks := []string{track.Address(), other.Address()}
+	identity := func(signer *junction.Signer) *trackIdentity {
+		return &trackIdentity{signer: signer, stationId: "station", addressPrefix: "air", tracks: tracks}
+	}
+
+	responder := testHost(t)
+	responderIdentity := identity(track)
+	admitted := make(chan error, 1)
+	responder.SetStreamHandler(protocol.ID(handshakeProtocolID), func(s network.Stream) {
+		defer s.Close()
+		_, err := responderIdentity.handshake(s, false)
+		admitted <- err
+	})
+
+	for _, tc := range []struct {
+		name   string
+		signer *junction.Signer
+		admit  bool
+	}{
+		{"track", other, true},
+		{"outsider", outsider, false},
+	} {
+		initiator := testHost(t)
+		if err := initiator.Connect(ctx, responder.Peerstore().PeerInfo(responder.ID())); err != nil {
+			t.Fatal(err)
+		}
+
+		address, err := identity(tc.signer).requestHandshake(ctx, initiator, responder.ID())
+		if err != nil || address != track.Address() {
+			t.Fatalf("%s: handshake returned %q, %v, want the address of the responder", tc.name, address, err)
+		}
+		if err = <-admitted; (err == nil) != tc.admit {
+			t.Errorf("%s: responder returned %v", tc.name, err)
+		}
+	}
+}
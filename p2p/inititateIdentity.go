@@ -1,51 +1,55 @@
 package p2p
 
 import (
+	"crypto/rand"
 	"fmt"
-	"github.com/libp2p/go-libp2p"
+	"github.com/airchains-network/decentralized-sequencer/config"
 	"github.com/libp2p/go-libp2p/core/crypto"
-	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"os"
 	"path/filepath"
 )
 
-type PeerGenerator struct {
-	ListenAddr string
-	PingConfig bool
-	PrivKey    crypto.PrivKey
-	Node       host.Host
+// IdentityPath is the file holding the libp2p key of the node. Its peer ID
+// is the one other tracks list in persistent_peers, so it must survive
+// restarts.
+func IdentityPath() string {
+	return config.ConfigPath(identityFileName)
 }
 
-func NewPeerGenerator(listenAddr string, pingConfig bool) *PeerGenerator {
-	return &PeerGenerator{
-		ListenAddr: listenAddr,
-		PingConfig: pingConfig,
+// LoadOrGenerateIdentity returns the node key stored at path, generating and
+// saving a new Ed25519 key when the file does not exist yet.
+func LoadOrGenerateIdentity(path string) (crypto.PrivKey, peer.ID, error) {
+	privateKey, err := loadPrivateKey(path)
+	if os.IsNotExist(err) {
+		privateKey, _, err = crypto.GenerateEd25519Key(rand.Reader)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to generate node key: %v", err)
+		}
+		serialized, err := crypto.MarshalPrivateKey(privateKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to marshal node key: %v", err)
+		}
+		if err = savePrivateKey(path, serialized); err != nil {
+			return nil, "", err
+		}
+	} else if err != nil {
+		return nil, "", fmt.Errorf("unable to read node key %s: %v", path, err)
 	}
-}
 
-func (pg *PeerGenerator) GeneratePeerID() (peer.ID, error) {
-	node, err := libp2p.New(
-		libp2p.ListenAddrStrings(pg.ListenAddr),
-		libp2p.Ping(pg.PingConfig),
-	)
+	peerID, err := peer.IDFromPrivateKey(privateKey)
 	if err != nil {
-		return "", err
+		return nil, "", fmt.Errorf("unable to derive peer ID from %s: %v", path, err)
 	}
+	return privateKey, peerID, nil
+}
 
-	privateKey, err := crypto.MarshalPrivateKey(node.Peerstore().PrivKey(node.ID()))
+func loadPrivateKey(filePath string) (crypto.PrivKey, error) {
+	serializedPrivKey, err := os.ReadFile(filePath)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	pg.Node = node
-	homeDir, _ := os.UserHomeDir()
-	filePath := filepath.Join(homeDir, ".tracks/config")
-	err = savePrivateKey(filepath.Join(filePath, "identity.info"), privateKey)
-	if err != nil {
-		return "", err
-	}
-
-	return node.ID(), nil
+	return crypto.UnmarshalPrivateKey(serializedPrivKey)
 }
 
 func savePrivateKey(filePath string, privateKey []byte) error {
@@ -55,7 +59,7 @@ func savePrivateKey(filePath string, privateKey []byte) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("unable to create directory: %v", err)
 	}
-	err := os.WriteFile(filePath, privateKey, 0644)
+	err := os.WriteFile(filePath, privateKey, 0600)
 	if err != nil {
 		return fmt.Errorf("unable to write private key to file: %v", err)
 	}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
 	logs "github.com/airchains-network/decentralized-sequencer/log"
-	"github.com/airchains-network/decentralized-sequencer/node/shared"
 	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/host"
@@ -13,13 +13,9 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
 	multiaddr "github.com/multiformats/go-multiaddr"
-	"io"
 	"math/big"
-	"os"
-	"os/signal"
 	"sort"
 	"sync"
-	"syscall"
 )
 
 const (
@@ -28,13 +24,8 @@ const (
 	MaxChunkSize     = 100
 )
 
-var (
-	incomingPeers  = NewPeerList()
-	peerListLocked = false
-	peerListLock   = sync.Mutex{}
-)
-
 type PeerList struct {
+	mu    sync.Mutex
 	peers []peer.AddrInfo
 }
 
@@ -47,15 +38,38 @@ type PodData struct {
 	PODs []*PodData
 }
 
+// AddPeer adds peerInfo, or replaces the entry of a peer already in the list.
 func (p *PeerList) AddPeer(peerInfo peer.AddrInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, known := range p.peers {
+		if known.ID == peerInfo.ID {
+			p.peers[i] = peerInfo
+			return
+		}
+	}
 	p.peers = append(p.peers, peerInfo)
 	sort.Slice(p.peers, func(i, j int) bool {
 		return p.peers[i].ID.String() < p.peers[j].ID.String()
 	})
 }
 
+// RemovePeer removes the peer with the given ID from the list.
+func (p *PeerList) RemovePeer(id peer.ID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, known := range p.peers {
+		if known.ID == id {
+			p.peers = append(p.peers[:i], p.peers[i+1:]...)
+			return
+		}
+	}
+}
+
 func (p *PeerList) GetPeers() []peer.AddrInfo {
-	return p.peers
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]peer.AddrInfo(nil), p.peers...)
 }
 
 func NewPeerList() *PeerList {
@@ -63,67 +77,34 @@ func NewPeerList() *PeerList {
 }
 
 var (
-	mutex    = &sync.Mutex{}
 	Node     host.Host
 	CTX      context.Context
 	peerList = NewPeerList()
 )
 
-// Your other functions...
-//func onConnected(n network.
-//	Network, c network.Conn) {
-//
-//	peerListLock.Lock()
-//	defer peerListLock.Unlock()
-//
-//	peerInfo := peer.AddrInfo{ID: c.RemotePeer(), Addrs: []multiaddr.Multiaddr{c.RemoteMultiaddr()}}
-//
-//	if peerListLocked {
-//		incomingPeers.AddPeer(peerInfo)
-//	} else {
-//		peerList.AddPeer(peerInfo)
-//	}
-//
-//	fmt.Printf("Connected to %s\n", c.RemotePeer())
-//
-//}
-
+// onConnected adds persistent peers to peerList. Inbound connections come
+// from an ephemeral port, so peers are matched by ID, not by multiaddr.
 func onConnected(n network.Network, c network.Conn) {
-	peerListLock.Lock()
-	defer peerListLock.Unlock()
-	baseConfig, err := shared.LoadConfig()
-	if err != nil {
-		fmt.Println("Error loading configuration")
+	if manager == nil || !manager.isPersistent(c.RemotePeer()) {
+		logs.Log.Debug(fmt.Sprintf("Connected to %s, not a persistent peer", c.RemotePeer()))
 		return
 	}
-
-	peerInfo := peer.AddrInfo{ID: c.RemotePeer(), Addrs: []multiaddr.Multiaddr{c.RemoteMultiaddr()}}
-	persistentPeers := baseConfig.P2P.PersistentPeers
-
-	for _, p := range persistentPeers {
-		peerMultiAddress := fmt.Sprintf("%s/p2p/%s", c.RemoteMultiaddr().String(), c.RemotePeer().String())
-		fmt.Println("peerMultiAddress: ", peerMultiAddress)
-		if p == peerMultiAddress {
-			peerList.AddPeer(peerInfo)
-
-			// update in shared AddConnectedPeer
-
-			fmt.Printf("Connected to %s\n", c.RemotePeer())
-			break
-		} else {
-			fmt.Println("Not connected to persistent peer")
-
-		}
-	}
+	peerList.AddPeer(peer.AddrInfo{ID: c.RemotePeer(), Addrs: []multiaddr.Multiaddr{c.RemoteMultiaddr()}})
 }
 
+// onDisconnected removes the peer from peerList once its last connection is
+// closed, and lets the peer manager redial it.
 func onDisconnected(n network.Network, c network.Conn) {
-	mutex.Lock()
-	defer mutex.Unlock()
-	// delete(ConnectedPeers, c.RemotePeer()) // Not used anymore
-	fmt.Printf("Disconnected from %s\n", c.RemotePeer())
-
-	// update in shared RemoveConnectedPeer
+	id := c.RemotePeer()
+	if manager != nil {
+		manager.peerDisconnected(id)
+	}
+	if n.Connectedness(id) == network.Connected {
+		return
+	}
+	peerList.RemovePeer(id)
+	dropStream(id)
+	logs.Log.Info(fmt.Sprintf("Disconnected from %s", id))
 }
 
 func getAllPeers(node host.Host) []peer.AddrInfo {
@@ -140,32 +121,57 @@ func getAllPeers(node host.Host) []peer.AddrInfo {
 }
 
 func startNode(ctx context.Context) (host.Host, error) {
-	//homeDir, _ := os.UserHomeDir()
-	//filePath := filepath.Join(homeDir, ".tracks/config/sequencer.toml")
-	//privateKey, err := loadPrivateKey(filePath)
-	//if err != nil {
-	//	return nil, fmt.Errorf("failed to load private key: %w", err)
-	//}
-	//fmt.Println(privateKey)
-
-	node, err := libp2p.New(
-		libp2p.ListenAddrStrings("/ip4/0.0.0.0/tcp/2300"),
-		libp2p.Ping(false),
-	)
+	baseConfig, err := config.Current()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create libp2p host: %w", err)
+		return nil, err
 	}
+	p2pConfig := baseConfig.P2P
 
-	registerConnectionHandlers(node)
-	return node, nil
-}
+	privateKey, peerID, err := LoadOrGenerateIdentity(IdentityPath())
+	if err != nil {
+		return nil, err
+	}
+	if p2pConfig.NodeId != "" && p2pConfig.NodeId != peerID {
+		logs.Log.Warn(fmt.Sprintf("p2p.node_id %s does not match the key in %s, running as %s", p2pConfig.NodeId, IdentityPath(), peerID))
+	}
 
-func loadPrivateKey(filepath string) (crypto.PrivKey, error) {
-	serializedPrivKey, err := os.ReadFile(filepath)
+	listenAddrs, err := p2pConfig.ListenMultiaddrs()
+	if err != nil {
+		return nil, fmt.Errorf("invalid p2p.listen_address: %w", err)
+	}
+	externalAddrs, err := p2pConfig.ExternalMultiaddrs()
+	if err != nil {
+		return nil, fmt.Errorf("invalid p2p.external_address: %w", err)
+	}
+
+	bans, err := loadBanList(BanListPath())
 	if err != nil {
 		return nil, err
 	}
-	return crypto.UnmarshalPrivateKey(serializedPrivKey)
+
+	options := []libp2p.Option{
+		libp2p.Identity(privateKey),
+		libp2p.ConnectionGater(bans),
+		libp2p.ListenAddrs(listenAddrs...),
+		libp2p.Ping(false),
+	}
+	if len(externalAddrs) > 0 {
+		// behind NAT or a load balancer, announce the reachable addresses
+		// instead of the local ones
+		options = append(options, libp2p.AddrsFactory(func([]multiaddr.Multiaddr) []multiaddr.Multiaddr {
+			return externalAddrs
+		}))
+	}
+
+	node, err := libp2p.New(options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create libp2p host: %w", err)
+	}
+
+	manager = newPeerManager(node, p2pConfig.PersistentPeers)
+	scores = newPeerScores(node, bans, p2pConfig)
+	registerConnectionHandlers(node)
+	return node, nil
 }
 
 func registerConnectionHandlers(node host.Host) {
@@ -192,119 +198,90 @@ func printNodePrivateKey(node host.Host) {
 	}
 }
 
-func connectToPeer(ctx context.Context, node host.Host, addrStr string) error {
-	peerInfo, err := parseAddrToPeerInfo(addrStr)
-	if err != nil {
-		return err
-	}
-	fmt.Printf("Connecting to %s\n", addrStr)
-	fmt.Println("node: ", node)
-	fmt.Println("CTX: ", CTX)
-	fmt.Println("peerInfo: ", peerInfo)
-	err = node.Connect(CTX, peerInfo)
-	if err != nil {
-		fmt.Printf("Unable to connect to peer. Error: %s", err.Error())
-	}
-	return err
-}
-
-func parseAddrToPeerInfo(addrStr string) (peer.AddrInfo, error) {
-	addr, err := multiaddr.NewMultiaddr(addrStr)
-	if err != nil {
-		return peer.AddrInfo{}, fmt.Errorf("parsing multiaddr failed: %w", err)
-	}
-	peerInfo, err := peer.AddrInfoFromP2pAddr(addr)
-	if err != nil {
-		return peer.AddrInfo{}, fmt.Errorf("creating peer info failed: %w", err)
-	}
-	return *peerInfo, nil
-}
-
 func setupStreamHandler(node host.Host) {
+	node.SetStreamHandler(protocol.ID(wireProtocolID), streamHandler)
 	node.SetStreamHandler(protocol.ID(customProtocolID), streamHandler)
-
 }
 
 func streamHandler(s network.Stream) {
 	defer s.Close()
-	handleStreamData(s)
+	if s.Protocol() == protocol.ID(customProtocolID) {
+		handleLegacyStream(s)
+		return
+	}
+	handleFramedStream(s)
 }
 
-func handleStreamData(s network.Stream) {
-	const initialBufSize = 8192
-	buf := make([]byte, initialBufSize)
-	messageBroadcaster := s.Conn().RemotePeer()
-	//fmt.Println(messageBroadcaster)
-	for {
-		n, err := s.Read(buf)
-		if err != nil {
-			if err != io.EOF {
-				fmt.Println("Failed to read from stream:", err)
-			} else {
-				fmt.Println("Stream closed by sender")
-			}
-			break
-		}
-
-		buf = resizeBufferIfNeeded(buf, n)
-
-		dataType, dataByte, err := DecodeGossipData(buf[:n])
-		if err != nil {
-			fmt.Println("Error in getting data type:", err)
-			return
-		}
-		fmt.Println("Data Type Received from other Peer :", dataType)
-
-		ProcessGossipMessage(Node, dataType, dataByte, messageBroadcaster)
+// sendMessage sends a signed message of dataType to peerID, framed on
+// wireProtocolID. Tracks that only speak customProtocolID can not check
+// signed messages and are skipped.
+func sendMessage(ctx context.Context, node host.Host, peerID peer.ID, dataType string, signed []byte) error {
+	if legacyOnly(node, peerID) {
+		return fmt.Errorf("track runs a version without signed messages")
 	}
+	return sendFrame(ctx, node, peerID, dataType, signed)
 }
 
-func resizeBufferIfNeeded(buf []byte, n int) []byte {
-	if n == len(buf) {
-		newSize := len(buf) * 2
-		return make([]byte, newSize)
-	}
-	return buf
+// legacyOnly reports whether peerID is known to speak only customProtocolID.
+func legacyOnly(node host.Host, peerID peer.ID) bool {
+	protocols, err := node.Peerstore().SupportsProtocols(peerID, protocol.ID(wireProtocolID), protocol.ID(customProtocolID))
+	return err == nil && len(protocols) == 1 && protocols[0] == protocol.ID(customProtocolID)
 }
 
-func sendMessage(ctx context.Context, node host.Host, peerID peer.ID, message []byte) error {
-	s, err := node.NewStream(ctx, peerID, protocol.ID(customProtocolID))
-	fmt.Printf("Sending message to %s\n", peerID)
+// BroadcastMessage signs a JSON types.GossipData with the junction key of
+// this track and sends it to every track: published on the gossip topic of
+// the station, and over direct streams to connected tracks that are not
+// subscribed to it.
+func BroadcastMessage(ctx context.Context, host host.Host, message []byte) {
+	dataType, data, err := DecodeGossipData(message)
 	if err != nil {
-		return fmt.Errorf("failed to open stream: %w", err)
+		logs.Log.Error(fmt.Sprintf("Invalid gossip message: %s", err))
+		return
 	}
-	defer s.Close()
-	_, err = s.Write(message)
+	if authenticator == nil {
+		logs.Log.Error(fmt.Sprintf("Can not send %s, the junction account is not loaded", dataType))
+		return
+	}
+	signed, err := authenticator.sign(dataType, data)
 	if err != nil {
-		return fmt.Errorf("failed to write message to stream: %w", err)
+		logs.Log.Error(fmt.Sprintf("Error signing %s: %s", dataType, err))
+		return
+	}
+	if podNumber, err := podNumberOf(data); err == nil {
+		recordStepSender(podNumber, dataType, authenticator.identity.signer.Address(), data)
+		observePodStep(podNumber, dataType)
 	}
 
-	return nil
-}
-
-func BroadcastMessage(ctx context.Context, host host.Host, message []byte) {
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	peers := peerList.GetPeers()
-	if len(peers) == 0 {
-		fmt.Println("No connected peers to send the message to.")
-		return
+	subscribed := make(map[peer.ID]bool)
+	if gossip != nil {
+		for _, id := range gossip.topic.ListPeers() {
+			subscribed[id] = true
+		}
+		if err = gossip.publish(ctx, dataType, signed); err != nil {
+			logs.Log.Error(fmt.Sprintf("Error publishing %s: %s", dataType, err))
+		}
 	}
 
-	for _, peerInfo := range peers {
-		if peerInfo.ID == host.ID() {
+	// one slow peer must not hold back the others
+	var wg sync.WaitGroup
+	for _, peerInfo := range peerList.GetPeers() {
+		if peerInfo.ID == host.ID() || subscribed[peerInfo.ID] {
 			continue
 		}
-		if err := sendMessage(ctx, host, peerInfo.ID, message); err != nil {
-			fmt.Printf("Error broadcasting message to %s: %s\n", peerInfo.ID, err)
-		}
+		wg.Add(1)
+		go func(id peer.ID) {
+			defer wg.Done()
+			if err := sendMessage(ctx, host, id, dataType, signed); err != nil {
+				logs.Log.Warn(fmt.Sprintf("Error sending %s to %s: %s", dataType, id, err))
+			}
+		}(peerInfo.ID)
 	}
+	wg.Wait()
 }
 
-func P2PConfiguration() {
-
-	ctx, cancel := context.WithCancel(context.Background())
+// P2PConfiguration runs the P2P node of the track until parent is done.
+func P2PConfiguration(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
 	defer cancel()
 	CTX = ctx
 	node, err := startNode(ctx)
@@ -315,27 +292,39 @@ func P2PConfiguration() {
 	defer Node.Close()
 
 	printNodeInfo(Node)
+	identity, err := newTrackIdentity()
+	if err != nil {
+		logs.Log.Error(fmt.Sprintf("Messages of other tracks can not be authenticated: %s", err))
+	} else {
+		authenticator = newMessageAuth(identity, currentPodHeight)
+	}
 	setupStreamHandler(Node)
+	if err = startGossip(ctx, Node); err != nil {
+		logs.Log.Error(fmt.Sprintf("%s, falling back to direct streams", err))
+	}
 	handlePeerConnections(ctx, Node)
-	waitForShutdownSignal()
+	<-ctx.Done()
+	logs.Log.Info("Shutting down P2P")
 }
 
+// handlePeerConnections starts dialing the persistent peers of the config,
+// and the discovery of other tracks when enabled.
 func handlePeerConnections(ctx context.Context, node host.Host) {
-	if len(os.Args) > 2 {
-		peerAddrStr := os.Args[2]
-		if err := connectToPeer(ctx, node, peerAddrStr); err != nil {
-			fmt.Println("Error connecting to peer:", err)
-		}
-	}
-}
+	manager.start(ctx)
 
-func waitForShutdownSignal() {
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, syscall.SIGTERM)
-	<-ch
-	fmt.Println("Received signal, shutting down...")
+	baseConfig, err := config.Current()
+	if err != nil {
+		logs.Log.Error(fmt.Sprintf("Error loading config: %s", err))
+		return
+	}
+	if err = startDiscovery(ctx, node, baseConfig.P2P); err != nil {
+		logs.Log.Error(err.Error())
+	}
 }
 
+// MasterTracksSelection returns the track leading the first round of the
+// pod that follows the pod with tracks app hash sharedInput. Every track
+// computes the same one.
 func MasterTracksSelection(host host.Host, sharedInput string) string {
 	peers := getAllPeers(host)
 	numPeers := len(peers)
@@ -343,50 +332,44 @@ func MasterTracksSelection(host host.Host, sharedInput string) string {
 		fmt.Println("No peers available.")
 		return ""
 	}
+	return peers[leaderIndex(sharedInput, numPeers)].ID.String()
+}
 
-	h := sha256.New()
-	h.Write([]byte(sharedInput))
-	hashed := h.Sum(nil)
-
-	// Convert the hash to a big.Int
-	hashedInt := new(big.Int)
-	hashedInt.SetBytes(hashed)
-
-	// Use modulus to get an index within the range of numPeers.
-	// randomIndex will always be in the range of 0 to numPeers-1 (inclusive).
-	randomIndex := hashedInt.Mod(hashedInt, big.NewInt(int64(numPeers)))
-	randomPeer := peers[int(randomIndex.Int64())]
-
-	// Need to re-compute hash and index if the randomly selected peer is the host itself
-	h = sha256.New()
-	h.Write([]byte(sharedInput))
-	hashed = h.Sum(nil)
-
-	hashedInt = new(big.Int)
-	hashedInt.SetBytes(hashed)
-
-	randomIndex = hashedInt.Mod(hashedInt, big.NewInt(int64(numPeers)))
-	randomPeer = peers[int(randomIndex.Int64())]
-
-	return randomPeer.ID.String()
+// leaderIndex maps sharedInput to an index in the range of 0 to n-1.
+func leaderIndex(sharedInput string, n int) int {
+	hashed := sha256.Sum256([]byte(sharedInput))
+	hashedInt := new(big.Int).SetBytes(hashed[:])
+	return int(hashedInt.Mod(hashedInt, big.NewInt(int64(n))).Int64())
 }
+
 func PeerConnectionStatus(host host.Host) bool {
 	peers := getAllPeers(host)
 	numPeers := len(peers)
-	baseConfig, err := shared.LoadConfig()
+	baseConfig, err := config.Current()
 	if err != nil {
 		logs.Log.Warn(fmt.Sprintf("Error loading config: %s", err))
 		return false
 	}
 
+	// with discovery, the peers are not listed in the config: wait for
+	// every track of the station instead
+	if baseConfig.P2P.MDNS || baseConfig.P2P.DHT {
+		tracks := len(baseConfig.Junction.Tracks)
+		if numPeers >= tracks {
+			return true
+		}
+		logs.Log.Warn(fmt.Sprintf("Connected to %d of %d tracks", numPeers, tracks))
+		return false
+	}
+
 	persistentPeers := baseConfig.P2P.PersistentPeers
 
 	if len(persistentPeers) == numPeers {
 		return true
 	} else {
 		logs.Log.Warn("All Node Not Connected to Persistent Peers")
-		logs.Log.Warn("Number of Persistent Peers: " + string(rune(len(persistentPeers))))
-		logs.Log.Warn("Number of Connected Peers: " + string(rune(numPeers)))
+		logs.Log.Warn(fmt.Sprintf("Number of Persistent Peers: %d", len(persistentPeers)))
+		logs.Log.Warn(fmt.Sprintf("Number of Connected Peers: %d", numPeers))
 		return false
 	}
 
@@ -0,0 +1,155 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	multiaddr "github.com/multiformats/go-multiaddr"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	minRedialBackoff = time.Second
+	maxRedialBackoff = time.Minute
+	// peerCheckInterval bounds how long a missed disconnect goes unnoticed
+	peerCheckInterval = 5 * time.Second
+	persistentTag     = "persistent"
+)
+
+// peerManager keeps the node connected to every persistent peer, and to the
+// tracks admitted by discovery: it dials them at startup and redials with
+// exponential backoff after a disconnect.
+type peerManager struct {
+	host  host.Host
+	ctx   context.Context
+	mu    sync.Mutex
+	peers map[peer.ID]peer.AddrInfo
+	// disconnected wakes up the dial loop of a peer
+	disconnected map[peer.ID]chan struct{}
+}
+
+// manager is created with the host in startNode.
+var manager *peerManager
+
+func newPeerManager(node host.Host, persistentPeers []string) *peerManager {
+	m := &peerManager{
+		host:         node,
+		peers:        make(map[peer.ID]peer.AddrInfo),
+		disconnected: make(map[peer.ID]chan struct{}),
+	}
+	for _, addr := range persistentPeers {
+		info, err := peer.AddrInfoFromString(addr)
+		if err != nil {
+			logs.Log.Warn(fmt.Sprintf("Ignoring persistent peer %q: %v", addr, err))
+			continue
+		}
+		if info.ID == node.ID() {
+			continue
+		}
+		if known, ok := m.peers[info.ID]; ok {
+			info.Addrs = append(known.Addrs, info.Addrs...)
+		}
+		m.peers[info.ID] = *info
+		m.disconnected[info.ID] = make(chan struct{}, 1)
+	}
+	return m
+}
+
+// isPersistent reports whether id is one of the persistent peers.
+func (m *peerManager) isPersistent(id peer.ID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.peers[id]
+	return ok
+}
+
+// start runs a dial loop for every persistent peer until ctx is done.
+func (m *peerManager) start(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ctx = ctx
+	for _, info := range m.peers {
+		m.watch(info)
+	}
+}
+
+// addPeer keeps the node connected to a peer admitted at runtime, like a
+// persistent one.
+func (m *peerManager) addPeer(info peer.AddrInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.peers[info.ID]; ok || m.ctx == nil {
+		return
+	}
+	m.peers[info.ID] = info
+	m.disconnected[info.ID] = make(chan struct{}, 1)
+	m.watch(info)
+}
+
+// watch starts the dial loop of info. m.mu must be held.
+func (m *peerManager) watch(info peer.AddrInfo) {
+	// the addresses of persistent peers never expire, and their
+	// connections are not trimmed by the connection manager
+	m.host.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.PermanentAddrTTL)
+	m.host.ConnManager().Protect(info.ID, persistentTag)
+	go m.keepConnected(m.ctx, info, m.disconnected[info.ID])
+}
+
+// peerDisconnected is called when a connection to id is closed. The dial
+// loop checks itself whether other connections are left.
+func (m *peerManager) peerDisconnected(id peer.ID) {
+	m.mu.Lock()
+	wake, ok := m.disconnected[id]
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+func (m *peerManager) keepConnected(ctx context.Context, info peer.AddrInfo, disconnected <-chan struct{}) {
+	backoff := minRedialBackoff
+	for {
+		if m.host.Network().Connectedness(info.ID) != network.Connected {
+			peerList.RemovePeer(info.ID)
+			if err := m.host.Connect(ctx, info); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+				logs.Log.Warn(fmt.Sprintf("Unable to connect to persistent peer %s, retrying in %s: %v", info.ID, wait.Round(time.Millisecond), err))
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+				if backoff *= 2; backoff > maxRedialBackoff {
+					backoff = maxRedialBackoff
+				}
+				continue
+			}
+			logs.Log.Info(fmt.Sprintf("Connected to persistent peer %s", info.ID))
+		}
+		// also covers peers that connected before the handlers were registered
+		if conns := m.host.Network().ConnsToPeer(info.ID); len(conns) > 0 {
+			peerList.AddPeer(peer.AddrInfo{ID: info.ID, Addrs: []multiaddr.Multiaddr{conns[0].RemoteMultiaddr()}})
+		}
+		backoff = minRedialBackoff
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-disconnected:
+		case <-time.After(peerCheckInterval):
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func inPeerList(h host.Host) bool {
+	for _, p := range peerList.GetPeers() {
+		if p.ID == h.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPeerManagerRedialsPersistentPeers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	remote, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+	local, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+
+	remoteAddr := fmt.Sprintf("%s/p2p/%s", remote.Addrs()[0], remote.ID())
+	manager = newPeerManager(local, []string{remoteAddr})
+	defer func() { manager = nil }()
+	registerConnectionHandlers(local)
+	manager.start(ctx)
+
+	waitFor(t, "the first dial", func() bool {
+		return inPeerList(remote) && len(remote.Network().ConnsToPeer(local.ID())) > 0
+	})
+
+	first := local.Network().ConnsToPeer(remote.ID())[0].ID()
+	if err = remote.Network().ClosePeer(local.ID()); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the redial", func() bool {
+		conns := local.Network().ConnsToPeer(remote.ID())
+		return len(conns) > 0 && conns[0].ID() != first && inPeerList(remote)
+	})
+}
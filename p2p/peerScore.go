@@ -0,0 +1,195 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"golang.org/x/time/rate"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Points added to the score of a peer. Peers start at 0 and are banned
+// once under p2p.ban_threshold.
+const (
+	penaltyInvalidMessage = -20 // bad signature, not a track, forged content
+	penaltyDecodeError    = -10 // malformed frame or JSON
+	penaltyWrongPod       = -5  // message of a pod this track is not at
+	penaltyTimeout        = -5  // the pod of a message never came
+	penaltyWrongSender    = -5  // pod step sent by a track not selected for it
+	penaltyRateLimited    = -2  // over p2p.message_rate_limit
+	rewardValidMessage    = 1
+
+	maxScore = 20
+	// scoreRecovery is how many points a penalized peer earns back per
+	// minute, so old mistakes are forgiven.
+	scoreRecovery = 1.0
+)
+
+type peerScore struct {
+	score    float64
+	updated  time.Time
+	limiters map[string]*rate.Limiter
+}
+
+// peerScores scores the peers on the messages they send and bans those
+// that misbehave. A nil *peerScores scores nothing.
+type peerScores struct {
+	host        host.Host
+	bans        *banList
+	threshold   float64
+	banDuration time.Duration
+	rateLimit   int
+
+	mu    sync.Mutex
+	peers map[peer.ID]*peerScore
+}
+
+// scores is created with the host in startNode.
+var scores *peerScores
+
+func newPeerScores(node host.Host, bans *banList, p2pConfig *config.P2PConfig) *peerScores {
+	return &peerScores{
+		host:        node,
+		bans:        bans,
+		threshold:   float64(p2pConfig.BanThreshold),
+		banDuration: p2pConfig.BanDuration,
+		rateLimit:   p2pConfig.MessageRateLimit,
+		peers:       make(map[peer.ID]*peerScore),
+	}
+}
+
+// get returns the score of id, with the points recovered since its last
+// update. It is called with mu held.
+func (s *peerScores) get(id peer.ID) *peerScore {
+	now := time.Now()
+	ps, ok := s.peers[id]
+	if !ok {
+		ps = &peerScore{updated: now, limiters: make(map[string]*rate.Limiter)}
+		s.peers[id] = ps
+	}
+	if ps.score < 0 {
+		ps.score += now.Sub(ps.updated).Minutes() * scoreRecovery
+		if ps.score > 0 {
+			ps.score = 0
+		}
+	}
+	ps.updated = now
+	return ps
+}
+
+// allow reports whether id may send one more message of dataType, and
+// penalizes it otherwise.
+func (s *peerScores) allow(id peer.ID, dataType string) bool {
+	if s == nil || s.rateLimit == 0 {
+		return true
+	}
+	s.mu.Lock()
+	ps := s.get(id)
+	limiter, ok := ps.limiters[dataType]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(s.rateLimit)), s.rateLimit)
+		ps.limiters[dataType] = limiter
+	}
+	allowed := limiter.Allow()
+	s.mu.Unlock()
+
+	if !allowed {
+		s.penalize(id, penaltyRateLimited, fmt.Errorf("more than %d %s messages per minute", s.rateLimit, dataType))
+	}
+	return allowed
+}
+
+// reward credits id for a valid message.
+func (s *peerScores) reward(id peer.ID) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ps := s.get(id)
+	if ps.score += rewardValidMessage; ps.score > maxScore {
+		ps.score = maxScore
+	}
+}
+
+// penalize takes points from id for reason, and bans it when its score
+// falls under the threshold.
+func (s *peerScores) penalize(id peer.ID, points int, reason error) {
+	if s == nil || points == 0 {
+		return
+	}
+	s.mu.Lock()
+	ps := s.get(id)
+	ps.score += float64(points)
+	score := ps.score
+	banned := score < s.threshold
+	if banned {
+		delete(s.peers, id)
+	}
+	s.mu.Unlock()
+
+	logs.Log.Warn(fmt.Sprintf("Peer %s scored %d (now %.1f): %v", id, points, score, reason))
+	if banned {
+		s.ban(id, reason)
+	}
+}
+
+func (s *peerScores) ban(id peer.ID, reason error) {
+	logs.Log.Warn(fmt.Sprintf("Banning peer %s for %s: %v", id, s.banDuration, reason))
+	if err := s.bans.add(id, s.banDuration, reason.Error()); err != nil {
+		logs.Log.Error(fmt.Sprintf("Error saving the ban list: %v", err))
+	}
+	if s.host != nil {
+		_ = s.host.Network().ClosePeer(id)
+	}
+}
+
+// penaltyFor is what an error of messageAuth.open costs the sender.
+// Replays and a pod state that is not loaded yet are not its fault, and
+// tracks of older versions send unsigned messages; those are dropped, and
+// only count against the rate limit.
+func penaltyFor(err error) int {
+	switch {
+	case errors.Is(err, errReplayedMessage), errors.Is(err, errNoPodState), errors.Is(err, errUnsignedMessage):
+		return 0
+	case errors.Is(err, errWrongPod):
+		return penaltyWrongPod
+	}
+	return penaltyInvalidMessage
+}
+
+// PeerScore is the score of a connected peer.
+type PeerScore struct {
+	ID    peer.ID `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// PeersStatus is the scores of the connected peers and the banned peers.
+type PeersStatus struct {
+	Peers  []PeerScore  `json:"peers"`
+	Banned []BannedPeer `json:"banned"`
+}
+
+// GetPeersStatus returns the scores of the tracks this node is connected to
+// and the peers it bans.
+func GetPeersStatus() PeersStatus {
+	status := PeersStatus{Peers: []PeerScore{}, Banned: []BannedPeer{}}
+	if scores == nil {
+		return status
+	}
+	scores.mu.Lock()
+	for _, info := range peerList.GetPeers() {
+		status.Peers = append(status.Peers, PeerScore{ID: info.ID, Score: scores.get(info.ID).score})
+	}
+	scores.mu.Unlock()
+	sort.Slice(status.Peers, func(i, j int) bool {
+		return status.Peers[i].ID < status.Peers[j].ID
+	})
+	status.Banned = scores.bans.list()
+	return status
+}
@@ -0,0 +1,84 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestMisbehavingPeerIsBanned(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), banListFileName)
+	bans, err := loadBanList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"), libp2p.ConnectionGater(bans))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer node.Close()
+	p2pConfig := config.DefaultP2PConfig()
+	p2pConfig.MessageRateLimit = 2
+	s := newPeerScores(node, bans, p2pConfig)
+
+	rogue := testHost(t)
+	rogueInfo := peer.AddrInfo{ID: rogue.ID(), Addrs: rogue.Addrs()}
+	if err = node.Connect(ctx, rogueInfo); err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.allow(rogue.ID(), "podVerified") || !s.allow(rogue.ID(), "podVerified") {
+		t.Fatal("messages under the rate limit refused")
+	}
+	if s.allow(rogue.ID(), "podVerified") {
+		t.Fatal("message over the rate limit allowed")
+	}
+	if !s.allow(rogue.ID(), "vrfInitiated") {
+		t.Fatal("rate limit is not per message type")
+	}
+
+	forged := errors.New("forged signature")
+	for i := 0; i < 4; i++ {
+		s.penalize(rogue.ID(), penaltyInvalidMessage, forged)
+	}
+	if bans.isBanned(rogue.ID()) {
+		t.Fatal("banned above the threshold")
+	}
+	s.penalize(rogue.ID(), penaltyInvalidMessage, forged)
+	if !bans.isBanned(rogue.ID()) {
+		t.Fatal("not banned under the threshold")
+	}
+	if node.Network().Connectedness(rogue.ID()) == network.Connected {
+		t.Fatal("banned peer still connected")
+	}
+	if err = node.Connect(ctx, rogueInfo); err == nil {
+		t.Fatal("dialed a banned peer")
+	}
+	waitFor(t, "the banned peer to see the disconnect", func() bool {
+		return rogue.Network().Connectedness(node.ID()) != network.Connected
+	})
+	// the dial of rogue may complete before node refuses the connection
+	_ = rogue.Connect(ctx, peer.AddrInfo{ID: node.ID(), Addrs: node.Addrs()})
+	waitFor(t, "node to refuse the banned peer", func() bool {
+		return rogue.Network().Connectedness(node.ID()) != network.Connected
+	})
+	if len(node.Network().ConnsToPeer(rogue.ID())) > 0 {
+		t.Fatal("accepted a banned peer")
+	}
+
+	reloaded, err := loadBanList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := reloaded.list()
+	if len(list) != 1 || list[0].ID != rogue.ID() || list[0].Reason != forged.Error() {
+		t.Fatalf("reloaded ban list %+v", list)
+	}
+}
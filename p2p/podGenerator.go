@@ -1,6 +1,7 @@
 package p2p
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/rand"
@@ -10,10 +11,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/airchains-network/decentralized-sequencer/da/avail"
-	"github.com/airchains-network/decentralized-sequencer/da/celestia"
-	"github.com/airchains-network/decentralized-sequencer/da/eigen"
-	mock "github.com/airchains-network/decentralized-sequencer/da/mockda"
+	"github.com/airchains-network/decentralized-sequencer/config"
 	"github.com/airchains-network/decentralized-sequencer/junction"
 	junctionTypes "github.com/airchains-network/decentralized-sequencer/junction/types"
 	logs "github.com/airchains-network/decentralized-sequencer/log"
@@ -24,9 +22,12 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func BatchGeneration(wg *sync.WaitGroup) {
+// BatchGeneration generates pods until ctx is done. A pod in progress is
+// not waited for: its state is saved at each step and resumed on restart.
+func BatchGeneration(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
-	GenerateUnverifiedPods()
+	go GenerateUnverifiedPods()
+	<-ctx.Done()
 }
 
 func GenerateUnverifiedPods() {
@@ -36,9 +37,8 @@ func GenerateUnverifiedPods() {
 		Str("module", "p2p").
 		Msg("Generating New unverified pods")
 
-	connection := shared.Node.NodeConnections
-	staticDBConnection := connection.GetStaticDatabaseConnection()
-	txnDBConnection := connection.GetTxnDatabaseConnection()
+	staticDBConnection := shared.Node.Store.Static()
+	txnDBConnection := shared.Node.Store.Txns()
 
 	rawConfirmedTransactionIndex, err := GetValueOrDefault(staticDBConnection, []byte(BatchStartIndexKey), []byte("0"))
 	CheckErrorAndExit(err, "Error in getting confirmedTransactionIndex from static db", 1)
@@ -87,7 +87,7 @@ func GenerateUnverifiedPods() {
 			previousTrackAppHash = []byte("nil")
 		}
 
-		baseCfg, err := shared.LoadConfig()
+		baseCfg, err := config.Current()
 		if err != nil {
 			log.Error().Str("module", "p2p").Msg("Error in loading config")
 		}
@@ -112,244 +112,44 @@ func GenerateUnverifiedPods() {
 		pMRH := podStateData.PreviousPodHash
 		batchInput = podStateData.Batch
 
-		storeNewPodState(trackAppHash, witness, uZKP, pMRH, MRH, uint64(batchNumber), batchInput, txState)
+		// the votes received before a restart still count
+		votes := make(map[string]shared.Votes)
+		if podStateData.LatestPodHeight == uint64(batchNumber) {
+			for voter, v := range podStateData.Votes {
+				votes[voter] = shared.Votes{PeerID: v.PeerID, Vote: v.Vote, Address: v.Address, TracksAppHash: v.TracksAppHash}
+			}
+		}
+		storeNewPodState(trackAppHash, witness, uZKP, pMRH, MRH, uint64(batchNumber), batchInput, txState, votes)
 	}
 
 	selectedMaster := MasterTracksSelection(Node, string(previousTrackAppHash))
 	decodedMaster, err := peer.Decode(selectedMaster)
 	CheckErrorAndExit(err, "Error in decoding master", 1)
+	// every track votes on the pod, and another track takes over when the
+	// master goes silent
+	if len(getAllPeers(Node)) > 1 {
+		castPodVote()
+		startPodRounds(uint64(batchNumber), string(previousTrackAppHash))
+	}
 
 	if decodedMaster == Node.ID() {
-		podState := shared.GetPodState()
-		currentVotes := podState.Votes
-		currentVotes[decodedMaster.String()] = shared.Votes{
-			PeerID: decodedMaster.String(),
-			Vote:   true,
-		}
-		podState.Votes = currentVotes
-		shared.SetPodState(podState)
 		Peers := getAllPeers(Node)
 		peerCount := len(Peers)
 		if peerCount == 1 {
-			DaData := shared.GetPodState().Batch.TransactionHash
-			var daDataByte []byte
-			for _, str := range DaData {
-				daDataByte = append(daDataByte, []byte(str)...)
-			}
-			ZkProof := shared.GetPodState().LatestPodProof
 			PodNumber := int(shared.GetPodState().LatestPodHeight)
 
-			finalizeDA := types.FinalizeDA{
-				CompressedHash: DaData,
-				Proof:          ZkProof,
-				PodNumber:      PodNumber,
-			}
-			_, err := json.Marshal(finalizeDA)
-			if err != nil {
-				logs.Log.Error("Error marshaling da data: " + err.Error())
-				os.Exit(1)
-			}
-
 			addr, err := junction.GetAddress()
 			if err != nil {
 				logs.Log.Error("Error in getting address")
 				os.Exit(1)
 			}
 
-			if shared.GetPodState().LatestTxState == shared.TxStateInitVRF {
-				success, _ := junction.InitVRF()
-				if !success {
-					logs.Log.Error("Failed to Init VRF")
-					os.Exit(1)
-				}
-				updateTxState(shared.TxStateVerifyVRF)
-			} else {
-				log.Debug().Str("module", "p2p").Msg("VRF is already initiated, moving to next step")
-			}
-
-			//os.Exit(0)
-
-			if shared.GetPodState().LatestTxState == shared.TxStateVerifyVRF {
-				success := junction.ValidateVRF(addr)
-				if !success {
-					logs.Log.Error("Failed to Validate VRF")
-					os.Exit(1)
-				}
-				updateTxState(shared.TxStateSubmitPod)
-
-				// check if VRF is successfully validated
-				var vrfRecord *junctionTypes.VrfRecord
-				vrfRecord = junction.QueryVRF()
-				if vrfRecord == nil {
-					logs.Log.Error("VRF record is nil")
-					os.Exit(1)
-				}
-				if !vrfRecord.IsVerified {
-					logs.Log.Error("Verification of VRF is failed, need Voting for correct VRN")
-					os.Exit(1)
-				}
-			} else {
-				log.Debug().Str("module", "p2p").Msg("VRF is already validated, moving to next step")
-			}
-			//os.Exit(0)
+			verifySingleTrackVRF(addr)
 
 			if shared.GetPodState().LatestTxState == shared.TxStateSubmitPod {
 
-				DaBatchSaver := connection.DataAvailabilityDatabaseConnection
-				baseConfig, err := shared.LoadConfig()
-				if err != nil {
-					fmt.Println("Error loading configuration")
-				}
-				Datype := baseConfig.DA.DaType
-				var (
-					daCheck    string
-					daCheckErr error
-				)
-
-				if Datype == "mock" {
-					mdb := connection.MockDatabaseConnection
-
-					for {
-						daCheck, daCheckErr = mock.MockDA(mdb, daDataByte, PodNumber)
-						if daCheckErr != nil {
-							logs.Log.Error("Error in submitting data to DA")
-							logs.Log.Debug("Retrying Mock DA after 10 seconds")
-							time.Sleep(10 * time.Second)
-						} else {
-							break
-						}
-					}
-
-					da := types.DAStruct{
-						DAKey:             daCheck,
-						DAClientName:      "mock-da",
-						BatchNumber:       strconv.Itoa(PodNumber),
-						PreviousStateHash: string(shared.GetPodState().PreviousPodHash),
-						CurrentStateHash:  string(shared.GetPodState().TracksAppHash),
-					}
-
-					daStoreKey := fmt.Sprintf("da-%d", PodNumber)
-					daStoreData, daStoreDataErr := json.Marshal(da)
-					if daStoreDataErr != nil {
-						logs.Log.Debug(fmt.Sprintf("Error in marshaling DA pointer : %s", daStoreDataErr.Error()))
-					}
-
-					storeErr := DaBatchSaver.Put([]byte(daStoreKey), daStoreData, nil)
-					if storeErr != nil {
-						logs.Log.Debug(fmt.Sprintf("Error in saving DA pointer in pod database : %s", storeErr.Error()))
-					}
-
-					log.Info().Str("module", "p2p").Msg("Data Saved in DA")
-
-				} else if Datype == "avail" {
-
-					for {
-						daCheck, daCheckErr = avail.Avail(daDataByte, baseConfig.DA.DaRPC)
-						if daCheckErr != nil {
-							logs.Log.Debug("Error in submitting data to DA " + daCheckErr.Error())
-							logs.Log.Debug("Retrying Avail DA after 10 seconds")
-							time.Sleep(10 * time.Second)
-						} else {
-							break
-						}
-					}
-
-					da := types.DAStruct{
-						DAKey:             daCheck,
-						DAClientName:      "avail-da",
-						BatchNumber:       strconv.Itoa(PodNumber),
-						PreviousStateHash: string(shared.GetPodState().PreviousPodHash),
-						CurrentStateHash:  string(shared.GetPodState().TracksAppHash),
-					}
-
-					daStoreKey := fmt.Sprintf("da-%d", PodNumber)
-					daStoreData, daStoreDataErr := json.Marshal(da)
-					if daStoreDataErr != nil {
-						logs.Log.Debug(fmt.Sprintf("Error in marshaling DA pointer : %s", daStoreDataErr.Error()))
-						return
-					}
-
-					storeErr := DaBatchSaver.Put([]byte(daStoreKey), daStoreData, nil)
-					if storeErr != nil {
-						logs.Log.Debug(fmt.Sprintf("Error in saving DA pointer in pod database : %s", storeErr.Error()))
-						return
-					}
-
-					log.Info().Str("module", "p2p").Msg("Data Saved in DA")
-
-				} else if Datype == "celestia" {
-					for {
-						daCheck, daCheckErr = celestia.Celestia(daDataByte, baseConfig.DA.DaRPC, baseConfig.DA.DaRPC)
-						if daCheckErr != nil {
-							logs.Log.Debug("Error in submitting data to DA " + daCheckErr.Error())
-							logs.Log.Debug("Retrying Celestia DA after 10 seconds")
-							time.Sleep(10 * time.Second)
-						} else {
-							break
-						}
-					}
-
-					da := types.DAStruct{
-						DAKey:             daCheck,
-						DAClientName:      "celestia-da",
-						BatchNumber:       strconv.Itoa(PodNumber),
-						PreviousStateHash: string(shared.GetPodState().PreviousPodHash),
-						CurrentStateHash:  string(shared.GetPodState().TracksAppHash),
-					}
-
-					daStoreKey := fmt.Sprintf("da-%d", PodNumber)
-					daStoreData, daStoreDataErr := json.Marshal(da)
-					if daStoreDataErr != nil {
-						logs.Log.Debug(fmt.Sprintf("Error in marshaling DA pointer : %s", daStoreDataErr.Error()))
-						return
-					}
-
-					storeErr := DaBatchSaver.Put([]byte(daStoreKey), daStoreData, nil)
-					if storeErr != nil {
-						logs.Log.Debug(fmt.Sprintf("Error in saving DA pointer in pod database : %s", storeErr.Error()))
-						return
-					}
-
-					log.Info().Str("module", "p2p").Msg("Data Saved in DA")
-
-				} else if Datype == "eigen" {
-
-					for {
-						daCheck, daCheckErr = eigen.Eigen(daDataByte, baseConfig.DA.DaRPC, baseConfig.DA.DaRPC)
-						if daCheckErr != nil {
-							logs.Log.Debug("Error in submitting data to DA " + daCheckErr.Error())
-							logs.Log.Debug("Retrying Eigen DA after 10 seconds")
-							time.Sleep(10 * time.Second)
-						} else {
-							break
-						}
-					}
-
-					da := types.DAStruct{
-						DAKey:             daCheck,
-						DAClientName:      "eigen-da",
-						BatchNumber:       strconv.Itoa(PodNumber),
-						PreviousStateHash: string(shared.GetPodState().PreviousPodHash),
-						CurrentStateHash:  string(shared.GetPodState().TracksAppHash),
-					}
-
-					daStoreKey := fmt.Sprintf("da-%d", PodNumber)
-					daStoreData, daStoreDataErr := json.Marshal(da)
-					if daStoreDataErr != nil {
-						logs.Log.Debug(fmt.Sprintf("Error in marshaling DA pointer : %s", daStoreDataErr.Error()))
-						return
-					}
-
-					storeErr := DaBatchSaver.Put([]byte(daStoreKey), daStoreData, nil)
-					if storeErr != nil {
-						logs.Log.Debug(fmt.Sprintf("Error in saving DA pointer in pod database : %s", storeErr.Error()))
-						return
-					}
-
-					log.Info().Str("module", "p2p").Msg("Data Saved in DA")
-
-				} else {
-					logs.Log.Error("Unknown layer. Please use 'avail' or 'celestia' as argument.")
+				if err := submitPodToDA(PodNumber, true); err != nil {
+					logs.Log.Error(err.Error())
 					return
 				}
 
@@ -382,64 +182,158 @@ func GenerateUnverifiedPods() {
 			saveVerifiedPOD()        // save data to database
 			GenerateUnverifiedPods() // generate next pod
 		} else {
-			PodNumber := int(shared.GetPodState().LatestPodHeight)
-			success, addr := junction.InitVRF()
+			initiatePodVRF()
+		}
+	}
+
+}
+
+// verifySingleTrackVRF initiates and validates the VRF of the pod on a
+// station with one track, until the junction verified it directly or after
+// a dispute; a VRN the dispute rejects is dropped for a new VRF. It resumes
+// from the tx state of the pod and, after a restart, from the recorded
+// VrfDisputeResult.
+func verifySingleTrackVRF(addr string) {
+	for {
+		if shared.GetPodState().LatestTxState == shared.TxStateInitVRF {
+			success, _ := junction.InitVRF()
 			if !success {
 				logs.Log.Error("Failed to Init VRF")
-				return
+				os.Exit(1)
 			}
-			logs.Log.Info("VRF initiated")
+			updateTxState(shared.TxStateVerifyVRF)
+		} else {
+			log.Debug().Str("module", "p2p").Msg("VRF is already initiated, moving to next step")
+		}
 
-			// get own address
-			_, _, accountPath, accountName, addressPrefix, tracks, err := junction.GetJunctionDetails()
-			if err != nil {
-				logs.Log.Error("can not get junctionDetails.json data: " + err.Error())
-				return
-			}
-			myAddress, err := junction.CheckIfAccountExists(accountName, accountPath, addressPrefix)
-			if err != nil {
-				logs.Log.Error("Can not get junction wallet address")
+		podState := shared.GetPodState()
+		txState := podState.LatestTxState
+		if result := podState.VrfDisputeResult; result != nil && (txState == shared.TxStateVerifyVRF || txState == shared.TxStateSubmitPod) {
+			// the dispute of this VRN was decided before a restart
+			if result.Result {
+				if txState == shared.TxStateVerifyVRF {
+					updateTxState(shared.TxStateSubmitPod)
+				}
 				return
 			}
+			logs.Log.Warn("VRN rejected by the VRF dispute: " + result.Message)
+			restartPodVRF()
+			continue
+		}
+		if txState != shared.TxStateVerifyVRF {
+			log.Debug().Str("module", "p2p").Msg("VRF is already validated, moving to next step")
+			return
+		}
 
-			// choose one verifiable random node to verify the VRF
-			// Filter out the peer with own Id
-			var filteredTracks []string
-			for _, track := range tracks {
-				if track != myAddress {
-					filteredTracks = append(filteredTracks, track)
-				}
-			}
-			// Select a random peer from the filtered list
-			selectedTrackAddress := filteredTracks[rand.Intn(len(filteredTracks))]
-			fmt.Println("Selected random address:", selectedTrackAddress)
-
-			// get txHash of vrfInit
-			VrfInitTxHash := shared.GetPodState().VRFInitiationTxHash
-			// send verify VRF message to selected node
-			VRFInitiatedMsg := VRFInitiatedMsgData{
-				PodNumber:            uint64(PodNumber),
-				SelectedTrackAddress: selectedTrackAddress,
-				VrfInitTxHash:        VrfInitTxHash,
-				VrfInitiatorAddress:  addr,
-			}
+		success := junction.ValidateVRF(addr)
+		if !success {
+			logs.Log.Error("Failed to Validate VRF")
+			os.Exit(1)
+		}
 
-			VRFInitiatedMsgByte, err := json.Marshal(VRFInitiatedMsg)
-			if err != nil {
-				logs.Log.Error("Error in Marshaling ProofVote Result")
-				return
-			}
-			gossipMsg := types.GossipData{
-				Type: "vrfInitiated",
-				Data: VRFInitiatedMsgByte,
-			}
-			gossipMsgByte, err := json.Marshal(gossipMsg)
-			if err != nil {
-				logs.Log.Error("Error marshaling gossip message")
-				return
-			}
-			BroadcastMessage(CTX, Node, gossipMsgByte)
+		// check if VRF is successfully validated
+		var vrfRecord *junctionTypes.VrfRecord
+		vrfRecord = junction.QueryVRF()
+		if vrfRecord == nil {
+			logs.Log.Error("VRF record is nil")
+			os.Exit(1)
+		}
+		if vrfRecord.IsVerified {
+			updateTxState(shared.TxStateSubmitPod)
+			return
+		}
+		logs.Log.Warn("Verification of VRF is failed, voting on the VRN")
+		result, err := disputeVRF(vrfRecord)
+		switch {
+		case err != nil:
+			logs.Log.Error(err.Error())
+		case result == nil:
+			logs.Log.Error("VRF dispute of this pod is already running")
+		case result.Result:
+			updateTxState(shared.TxStateSubmitPod)
+			return
+		default:
+			logs.Log.Warn("VRN rejected by the VRF dispute: " + result.Message)
 		}
+		restartPodVRF()
+	}
+}
+
+// restartPodVRF drops the VRF of the current pod, and its dispute, so a
+// new VRF is initiated.
+func restartPodVRF() {
+	logs.Log.Info("Initiating a new VRF for the pod")
+	time.Sleep(SleepDuration)
+	resetVrfDispute(shared.GetPodState().LatestPodHeight)
+	shared.UpdatePodState(func(podState *shared.PodState) {
+		podState.LatestTxState = shared.TxStateInitVRF
+		podState.VrfDisputeResult = nil
+		updatePodStateInDatabase(podState)
+	})
+}
+
+// initiatePodVRF is the leader part of a pod on a station with several
+// tracks: it initiates the VRF and asks a random other track to verify it.
+func initiatePodVRF() {
+	PodNumber := int(shared.GetPodState().LatestPodHeight)
+	if err := waitForQuorum(uint64(PodNumber)); err != nil {
+		logs.Log.Error(fmt.Sprintf("Not initiating the VRF: %v", err))
+		return
 	}
+	success, addr := junction.InitVRF()
+	if !success {
+		logs.Log.Error("Failed to Init VRF")
+		return
+	}
+	logs.Log.Info("VRF initiated")
 
+	// get own address
+	_, _, accountPath, accountName, addressPrefix, tracks, err := junction.GetJunctionDetails()
+	if err != nil {
+		logs.Log.Error("can not get junctionDetails.json data: " + err.Error())
+		return
+	}
+	myAddress, err := junction.CheckIfAccountExists(accountName, accountPath, addressPrefix)
+	if err != nil {
+		logs.Log.Error("Can not get junction wallet address")
+		return
+	}
+
+	// choose one verifiable random node to verify the VRF
+	// Filter out the peer with own Id
+	var filteredTracks []string
+	for _, track := range tracks {
+		if track != myAddress {
+			filteredTracks = append(filteredTracks, track)
+		}
+	}
+	// Select a random peer from the filtered list
+	selectedTrackAddress := filteredTracks[rand.Intn(len(filteredTracks))]
+	fmt.Println("Selected random address:", selectedTrackAddress)
+
+	// get txHash of vrfInit
+	VrfInitTxHash := shared.GetPodState().VRFInitiationTxHash
+	// send verify VRF message to selected node
+	VRFInitiatedMsg := VRFInitiatedMsgData{
+		PodNumber:            uint64(PodNumber),
+		SelectedTrackAddress: selectedTrackAddress,
+		VrfInitTxHash:        VrfInitTxHash,
+		VrfInitiatorAddress:  addr,
+	}
+
+	VRFInitiatedMsgByte, err := json.Marshal(VRFInitiatedMsg)
+	if err != nil {
+		logs.Log.Error("Error in Marshaling ProofVote Result")
+		return
+	}
+	gossipMsg := types.GossipData{
+		Type: "vrfInitiated",
+		Data: VRFInitiatedMsgByte,
+	}
+	gossipMsgByte, err := json.Marshal(gossipMsg)
+	if err != nil {
+		logs.Log.Error("Error marshaling gossip message")
+		return
+	}
+	BroadcastMessage(CTX, Node, gossipMsgByte)
 }
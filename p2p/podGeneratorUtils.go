@@ -5,15 +5,17 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/blocksync"
 	"github.com/airchains-network/decentralized-sequencer/config"
 	logs "github.com/airchains-network/decentralized-sequencer/log"
 	"github.com/airchains-network/decentralized-sequencer/node/shared"
+	"github.com/airchains-network/decentralized-sequencer/store"
 	"github.com/airchains-network/decentralized-sequencer/types"
 	utilis "github.com/airchains-network/decentralized-sequencer/utils"
 	v1 "github.com/airchains-network/decentralized-sequencer/zk/v1EVM"
 	v1Wasm "github.com/airchains-network/decentralized-sequencer/zk/v1WASM"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/rs/zerolog/log"
-	"github.com/syndtr/goleveldb/leveldb"
 	"os"
 	"strconv"
 	"strings"
@@ -36,18 +38,18 @@ func CheckErrorAndExit(err error, message string, exitCode int) {
 	}
 }
 
-func GetValueOrDefault(db *leveldb.DB, key []byte, defaultValue []byte) ([]byte, error) {
-	val, err := db.Get(key, nil)
+func GetValueOrDefault(db store.Namespace, key []byte, defaultValue []byte) ([]byte, error) {
+	val, err := db.Get(key)
 	if err != nil {
 		logs.Log.Warn(fmt.Sprintf("%s not found in static db", string(key)))
-		err = db.Put(key, defaultValue, nil)
+		err = db.Put(key, defaultValue)
 		CheckErrorAndExit(err, fmt.Sprintf("Error in saving %s in static db", string(key)), 0)
 	}
 	return val, nil
 }
 
-func createEVMPOD(ldt *leveldb.DB, batchStartIndex []byte, limit []byte) (witness []byte, unverifiedProof []byte, MRH []byte, podData *types.BatchStruct, err error) {
-	baseConfig, err := shared.LoadConfig()
+func createEVMPOD(ldt store.Namespace, batchStartIndex []byte, limit []byte) (witness []byte, unverifiedProof []byte, MRH []byte, podData *types.BatchStruct, err error) {
+	baseConfig, err := config.Current()
 	if err != nil {
 		return
 	}
@@ -70,7 +72,7 @@ func createEVMPOD(ldt *leveldb.DB, batchStartIndex []byte, limit []byte) (witnes
 	for i := batchStartIndexInt; i < (config.PODSize * (limitInt + 1)); i++ {
 
 		findKey := fmt.Sprintf("txns-%d", i+1)
-		txData, err := ldt.Get([]byte(findKey), nil)
+		txData, err := ldt.Get([]byte(findKey))
 		if err != nil {
 			i--
 			time.Sleep(1 * time.Second)
@@ -83,6 +85,18 @@ func createEVMPOD(ldt *leveldb.DB, batchStartIndex []byte, limit []byte) (witnes
 			os.Exit(0)
 		}
 
+		// a reverted transaction stays in the pod but transfers no value
+		amount := tx.Value
+		receipt, err := blocksync.GetEVMReceipt(ldt, i+1)
+		if err != nil && err != store.ErrNotFound {
+			logs.Log.Error(fmt.Sprintf("Error in getting receipt of %s : %s", tx.Hash, err.Error()))
+			os.Exit(0)
+		}
+		if receipt != nil && receipt.Status == ethTypes.ReceiptStatusFailed {
+			log.Debug().Str("module", "p2p").Msg(fmt.Sprintf("Transaction %s reverted, batching it with zero amount", tx.Hash))
+			amount = "0"
+		}
+
 		senderBalancesCheck, err := utilis.GetBalance(tx.From, tx.BlockNumber-1, baseConfig.Station.StationRPC)
 		if err != nil {
 			logs.Log.Error(fmt.Sprintf("Error in getting sender balance : %s", err.Error()))
@@ -103,7 +117,7 @@ func createEVMPOD(ldt *leveldb.DB, batchStartIndex []byte, limit []byte) (witnes
 
 		From = append(From, tx.From)
 		To = append(To, tx.To)
-		Amounts = append(Amounts, tx.Value)
+		Amounts = append(Amounts, amount)
 		TransactionHash = append(TransactionHash, tx.Hash)
 		SenderBalances = append(SenderBalances, senderBalancesCheck)
 		ReceiverBalances = append(ReceiverBalances, receiverBalancesCheck)
@@ -143,8 +157,8 @@ func createEVMPOD(ldt *leveldb.DB, batchStartIndex []byte, limit []byte) (witnes
 
 	return witnessVectorByte, proofByte, currentStatusHashByte, &batch, nil
 }
-func createWasmPOD(ldt *leveldb.DB, batchStartIndex []byte, limit []byte) (witness []byte, unverifiedProof []byte, MRH []byte, podData *types.BatchStruct, err error) {
-	baseConfig, err := shared.LoadConfig()
+func createWasmPOD(ldt store.Namespace, batchStartIndex []byte, limit []byte) (witness []byte, unverifiedProof []byte, MRH []byte, podData *types.BatchStruct, err error) {
+	baseConfig, err := config.Current()
 	if err != nil {
 		return
 	}
@@ -165,7 +179,7 @@ func createWasmPOD(ldt *leveldb.DB, batchStartIndex []byte, limit []byte) (witne
 
 	for i := batchStartIndexInt; i < (config.PODSize * (limitInt + 1)); i++ {
 		findKey := fmt.Sprintf("txns-%d", i+1)
-		txData, err := ldt.Get([]byte(findKey), nil)
+		txData, err := ldt.Get([]byte(findKey))
 
 		if err != nil {
 			i--
@@ -231,40 +245,39 @@ func createWasmPOD(ldt *leveldb.DB, batchStartIndex []byte, limit []byte) (witne
 }
 func saveVerifiedPOD() {
 
-	podState := shared.GetPodState()
-	batchTimestamp := time.Now()
-	podState.Timestamp = &batchTimestamp
-	currentPodNumber := podState.LatestPodHeight
+	var (
+		currentPodNumber             uint64
+		batchInputWithTimestampBytes []byte
+		err                          error
+	)
+	shared.UpdatePodState(func(podState *shared.PodState) {
+		batchTimestamp := time.Now()
+		podState.Timestamp = &batchTimestamp
+		currentPodNumber = podState.LatestPodHeight
+		batchInputWithTimestampBytes, err = json.Marshal(podState)
+	})
 	currentPodNumberInt := int(currentPodNumber)
 
-	lds := shared.Node.NodeConnections.GetStaticDatabaseConnection()
-
-	err := lds.Put([]byte("batchStartIndex"), []byte(strconv.Itoa(config.PODSize*(currentPodNumberInt))), nil)
-	if err != nil {
-		logs.Log.Error(fmt.Sprintf("Error in updating batchStartIndex in static db : %s", err.Error()))
-		os.Exit(0)
-	}
-
-	err = lds.Put([]byte("batchCount"), []byte(strconv.Itoa(currentPodNumberInt)), nil)
-	if err != nil {
-		logs.Log.Error(fmt.Sprintf("Error in updating batchCount in static db : %s", err.Error()))
-		os.Exit(0)
-	}
-
-	batchDB := shared.Node.NodeConnections.GetPodsDatabaseConnection()
+	db := shared.Node.Store
 	podKey := fmt.Sprintf("pod-%d", currentPodNumberInt)
 
-	batchInputWithTimestampBytes, err := json.Marshal(podState)
 	if err != nil {
 		logs.Log.Error(fmt.Sprintf("Error in marshalling batch data : %s", err.Error()))
 		os.Exit(0)
 	}
-	err = batchDB.Put([]byte(podKey), batchInputWithTimestampBytes, nil)
-	if err != nil {
-		panic("Failed to update pod data: " + err.Error())
+
+	// pod-N and the counters moving past it are saved together
+	batch := new(store.Batch)
+	batch.Put(db.Pods(), []byte(podKey), batchInputWithTimestampBytes)
+	batch.Put(db.Static(), []byte("batchStartIndex"), []byte(strconv.Itoa(config.PODSize*(currentPodNumberInt))))
+	batch.Put(db.Static(), []byte("batchCount"), []byte(strconv.Itoa(currentPodNumberInt)))
+	if err = db.Write(batch); err != nil {
+		logs.Log.Error(fmt.Sprintf("Error in saving pod and updating batchStartIndex and batchCount : %s", err.Error()))
+		os.Exit(0)
 	}
-	podState.MasterTrackAppHash = nil
-	shared.SetPodState(podState)
+	shared.UpdatePodState(func(podState *shared.PodState) {
+		podState.MasterTrackAppHash = nil
+	})
 
 	log.Info().Str("module", "p2p").Msg("Present Pod has been saved Locally")
 }
@@ -276,9 +289,8 @@ func generatePodHash(Witness, uZKP, MRH []byte, podNumber []byte) []byte {
 	hash.Write(podNumber)
 	return hash.Sum(nil)
 }
-func storeNewPodState(CombinedPodHash, Witness, uZKP, previousMRH, MRH []byte, podNumber uint64, batchInput *types.BatchStruct, txState string) {
+func storeNewPodState(CombinedPodHash, Witness, uZKP, previousMRH, MRH []byte, podNumber uint64, batchInput *types.BatchStruct, txState string, votes map[string]shared.Votes) {
 	var podState *shared.PodState
-	votes := make(map[string]shared.Votes)
 	podState = &shared.PodState{
 		LatestPodHeight:     podNumber,
 		LatestTxState:       txState,
@@ -311,13 +323,13 @@ func updateNewPodState(CombinedPodHash, Witness, uZKP, MRH []byte, podNumber uin
 	updatePodStateInDatabase(podState)
 }
 func updateTxState(txState string) {
-	podState := shared.GetPodState()
-	podState.LatestTxState = txState
-	shared.SetPodState(podState)
-	updatePodStateInDatabase(podState)
+	shared.UpdatePodState(func(podState *shared.PodState) {
+		podState.LatestTxState = txState
+		updatePodStateInDatabase(podState)
+	})
 }
 func updatePodStateInDatabase(podState *shared.PodState) {
-	stateConnection := shared.Node.NodeConnections.GetStateDatabaseConnection()
+	stateConnection := shared.Node.Store.PodState()
 
 	podStateByte, err := json.Marshal(podState)
 	if err != nil {
@@ -325,16 +337,16 @@ func updatePodStateInDatabase(podState *shared.PodState) {
 		os.Exit(0)
 	}
 
-	err = stateConnection.Put([]byte("podState"), podStateByte, nil)
+	err = stateConnection.Put([]byte("podState"), podStateByte)
 	if err != nil {
 		logs.Log.Error(err.Error())
 	}
 }
 func GetPodStateFromDatabase() (*types.PodState, error) {
 	var podStateData *types.PodState
-	stateConnection := shared.Node.NodeConnections.GetStateDatabaseConnection()
+	stateConnection := shared.Node.Store.PodState()
 
-	podStateDataByte, err := stateConnection.Get([]byte("podState"), nil)
+	podStateDataByte, err := stateConnection.Get([]byte("podState"))
 	if err != nil {
 		logs.Log.Error("error in getting pod state data from database")
 		return nil, err
@@ -0,0 +1,203 @@
+package p2p
+
+import (
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"sync"
+	"time"
+)
+
+// podSteps are the messages that move a pod forward, in order.
+var podSteps = []string{"vrfInitiated", "vrnValidated", "podSubmitted", "podVerified"}
+
+func podStepIndex(dataType string) int {
+	for i, step := range podSteps {
+		if step == dataType {
+			return i
+		}
+	}
+	return -1
+}
+
+// podRounds rotates the leadership of a pod between the tracks. Round 0 is
+// led by the master track; each time the pod does not move forward within
+// the timeout of its current step, the next track in candidates leads a new
+// round for the same pod.
+type podRounds struct {
+	podNumber  uint64
+	candidates []peer.ID
+	first      int
+	self       peer.ID
+	timeouts   *config.ConsensusConfig
+	// lead runs the leader part of the pod on this track
+	lead func()
+
+	mu    sync.Mutex
+	round int
+	// step is the index in podSteps of the next expected message
+	step     int
+	progress chan struct{}
+	stop     chan struct{}
+}
+
+var (
+	roundsMu sync.Mutex
+	rounds   *podRounds
+	// earlySteps are the steps seen for a pod before its rounds started
+	earlySteps = map[uint64]int{}
+)
+
+func newPodRounds(podNumber uint64, candidates []peer.ID, first int, self peer.ID, timeouts *config.ConsensusConfig, lead func()) *podRounds {
+	return &podRounds{
+		podNumber:  podNumber,
+		candidates: candidates,
+		first:      first,
+		self:       self,
+		timeouts:   timeouts,
+		lead:       lead,
+		progress:   make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+	}
+}
+
+// startPodRounds watches the pod podNumber, replacing the rounds of the
+// previous pod. seed is what MasterTracksSelection was given.
+func startPodRounds(podNumber uint64, seed string) {
+	baseConfig, err := config.Current()
+	if err != nil {
+		logs.Log.Error(fmt.Sprintf("Leader rotation is off, error loading config: %s", err))
+		return
+	}
+	peers := getAllPeers(Node)
+	candidates := make([]peer.ID, len(peers))
+	for i, p := range peers {
+		candidates[i] = p.ID
+	}
+	r := newPodRounds(podNumber, candidates, leaderIndex(seed, len(candidates)), Node.ID(), baseConfig.Consensus, initiatePodVRF)
+
+	roundsMu.Lock()
+	if rounds != nil {
+		close(rounds.stop)
+	}
+	rounds = r
+	step, ok := earlySteps[podNumber]
+	earlySteps = map[uint64]int{}
+	roundsMu.Unlock()
+
+	if ok {
+		r.observe(step)
+	}
+	go r.run()
+}
+
+// observePodStep records that the message dataType of the pod podNumber was
+// sent or received.
+func observePodStep(podNumber uint64, dataType string) {
+	step := podStepIndex(dataType)
+	if step < 0 {
+		return
+	}
+	roundsMu.Lock()
+	r := rounds
+	if r == nil || podNumber > r.podNumber {
+		if seen, ok := earlySteps[podNumber]; !ok || step > seen {
+			earlySteps[podNumber] = step
+		}
+		roundsMu.Unlock()
+		return
+	}
+	roundsMu.Unlock()
+	if podNumber == r.podNumber {
+		r.observe(step)
+	}
+}
+
+func (r *podRounds) observe(step int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if step+1 <= r.step {
+		return
+	}
+	r.step = step + 1
+	select {
+	case r.progress <- struct{}{}:
+	default:
+	}
+}
+
+// leader returns the track leading round.
+func (r *podRounds) leader(round int) peer.ID {
+	return r.candidates[(r.first+round)%len(r.candidates)]
+}
+
+// timeout is how long round waits for its next step. Later rounds wait
+// longer, so a slow network converges instead of rotating forever.
+func (r *podRounds) timeout() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	round := time.Duration(r.round)
+	t := r.timeouts
+	if r.step >= len(podSteps) {
+		return t.TimeoutCommit
+	}
+	switch podSteps[r.step] {
+	case "vrfInitiated":
+		return t.TimeoutPropose + round*t.TimeoutProposeDelta
+	case "vrnValidated":
+		return t.TimeoutPrevote + round*t.TimeoutPrevoteDelta
+	case "podSubmitted":
+		return t.TimeoutPrecommit + round*t.TimeoutPrecommitDelta
+	}
+	return t.TimeoutCommit
+}
+
+func (r *podRounds) done() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.step >= len(podSteps)
+}
+
+func (r *podRounds) run() {
+	if r.leader(0) == r.self {
+		logs.Log.Info(fmt.Sprintf("Leading pod %d", r.podNumber))
+	}
+	// the pod may be done before the rounds start, with its steps seen early,
+	// or between two turns of the loop
+	for !r.done() {
+		timer := time.NewTimer(r.timeout())
+		select {
+		case <-r.stop:
+			timer.Stop()
+			return
+		case <-r.progress:
+			timer.Stop()
+		case <-timer.C:
+			r.nextRound()
+		}
+	}
+}
+
+// nextRound hands the pod to the next track, which starts over from the VRF.
+func (r *podRounds) nextRound() {
+	r.mu.Lock()
+	if r.step >= len(podSteps) {
+		// the pod got verified as the timer fired
+		r.mu.Unlock()
+		return
+	}
+	silent := r.leader(r.round)
+	stalled := podSteps[r.step]
+	r.round++
+	r.step = 0
+	round := r.round
+	r.mu.Unlock()
+
+	leader := r.leader(round)
+	logs.Log.Warn(fmt.Sprintf("Pod %d got no %s in time from the round led by %s, round %d is led by %s", r.podNumber, stalled, silent, round, leader))
+	if leader == r.self {
+		logs.Log.Info(fmt.Sprintf("Taking over pod %d in round %d", r.podNumber, round))
+		go r.lead()
+	}
+}
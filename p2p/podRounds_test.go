@@ -0,0 +1,98 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestPodRoundsHandOverSilentLeader(t *testing.T) {
+	timeouts := &config.ConsensusConfig{
+		TimeoutPropose:        100 * time.Millisecond,
+		TimeoutProposeDelta:   100 * time.Millisecond,
+		TimeoutPrevote:        100 * time.Millisecond,
+		TimeoutPrecommit:      100 * time.Millisecond,
+		TimeoutCommit:         100 * time.Millisecond,
+		TimeoutPrevoteDelta:   0,
+		TimeoutPrecommitDelta: 0,
+	}
+	candidates := []peer.ID{"leader", "self", "third"}
+
+	// the leader keeps the pod moving: nobody takes over
+	led := make(chan int, 10)
+	r := newPodRounds(7, candidates, 0, "self", timeouts, func() { led <- 1 })
+	go r.run()
+	for step := range podSteps {
+		time.Sleep(50 * time.Millisecond)
+		r.observe(step)
+	}
+	select {
+	case <-led:
+		t.Fatal("took over a pod that was moving")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// the leader goes silent after the VRF: the next track takes over
+	r = newPodRounds(7, candidates, 0, "self", timeouts, func() { led <- 1 })
+	defer close(r.stop)
+	go r.run()
+	r.observe(podStepIndex("vrfInitiated"))
+	start := time.Now()
+	select {
+	case <-led:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no take over of a silent leader")
+	}
+	if elapsed := time.Since(start); elapsed < timeouts.TimeoutPrevote {
+		t.Fatalf("took over after %s, before the prevote timeout", elapsed)
+	}
+	r.mu.Lock()
+	round, step := r.round, r.step
+	r.mu.Unlock()
+	if round != 1 || step != 0 || r.leader(round) != "self" {
+		t.Fatalf("round %d at step %d led by %s", round, step, r.leader(round))
+	}
+
+	// round 1 is silent too: the third track leads round 2, not this one
+	select {
+	case <-led:
+		t.Fatal("led a round of another track")
+	case <-time.After(timeouts.TimeoutPropose + timeouts.TimeoutProposeDelta + 100*time.Millisecond):
+	}
+	r.mu.Lock()
+	round = r.round
+	r.mu.Unlock()
+	if round != 2 || r.leader(round) != "third" {
+		t.Fatalf("round %d led by %s, want round 2 led by third", round, r.leader(round))
+	}
+}
+
+func TestPodRoundsOfVerifiedPodStop(t *testing.T) {
+	timeouts := &config.ConsensusConfig{TimeoutPropose: 50 * time.Millisecond, TimeoutCommit: 50 * time.Millisecond}
+	led := make(chan int, 1)
+	r := newPodRounds(7, []peer.ID{"leader", "self"}, 0, "self", timeouts, func() { led <- 1 })
+	defer close(r.stop)
+	// a track that restarted mid pod saw its podVerified before the rounds
+	r.observe(podStepIndex("podVerified"))
+	if got := r.timeout(); got != timeouts.TimeoutCommit {
+		t.Fatalf("timeout %s after the pod was verified", got)
+	}
+	finished := make(chan struct{})
+	go func() {
+		r.run()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("rounds of a verified pod kept running")
+	}
+	r.nextRound()
+	select {
+	case <-led:
+		t.Fatal("took over a verified pod")
+	default:
+	}
+}
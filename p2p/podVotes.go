@@ -0,0 +1,234 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/airchains-network/decentralized-sequencer/node/shared"
+	"github.com/airchains-network/decentralized-sequencer/types"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"time"
+)
+
+// PodDisagreement is a vote for another TracksAppHash than the one this
+// track derived, kept in the disagreements store.
+type PodDisagreement struct {
+	PodNumber        uint64    `json:"podNumber"`
+	Voter            string    `json:"voter"`
+	PeerID           string    `json:"peerId"`
+	TracksAppHash    []byte    `json:"tracksAppHash"`
+	OwnTracksAppHash []byte    `json:"ownTracksAppHash"`
+	Time             time.Time `json:"time"`
+}
+
+// castPodVote sends the TracksAppHash this track derived for the current
+// pod to the other tracks, and records it as its own vote.
+func castPodVote() {
+	if authenticator == nil {
+		logs.Log.Warn("Not voting on the pod, the junction account is not loaded")
+		return
+	}
+	vote := ownPodVote()
+	recordPodVote(vote, Node.ID())
+
+	voteByte, err := json.Marshal(vote)
+	if err != nil {
+		logs.Log.Error("Error in Marshaling pod vote")
+		return
+	}
+	gossipMsgByte, err := json.Marshal(types.GossipData{Type: "podVote", Data: voteByte})
+	if err != nil {
+		logs.Log.Error(LogMarshalGossipMsg)
+		return
+	}
+	BroadcastMessage(CTX, Node, gossipMsgByte)
+}
+
+func ownPodVote() PodVoteMsgData {
+	podState := shared.GetPodState()
+	return PodVoteMsgData{
+		PodNumber:     podState.LatestPodHeight,
+		Voter:         authenticator.identity.signer.Address(),
+		TracksAppHash: podState.TracksAppHash,
+	}
+}
+
+// PodVoteMsgHandler records the vote of another track.
+func PodVoteMsgHandler(dataByte []byte, from peer.ID) {
+	var vote PodVoteMsgData
+	if err := json.Unmarshal(dataByte, &vote); err != nil {
+		scores.penalize(from, penaltyDecodeError, err)
+		return
+	}
+	recordPodVote(vote, from)
+}
+
+// requestPodVotes asks the other tracks to send their vote on podNumber
+// again, for a leader that lost the votes sent before it restarted.
+func requestPodVotes(podNumber uint64) {
+	msgByte, err := json.Marshal(PodVoteRequestMsgData{PodNumber: podNumber})
+	if err != nil {
+		logs.Log.Error("Error in Marshaling pod vote request")
+		return
+	}
+	gossipMsgByte, err := json.Marshal(types.GossipData{Type: "podVoteRequest", Data: msgByte})
+	if err != nil {
+		logs.Log.Error(LogMarshalGossipMsg)
+		return
+	}
+	BroadcastMessage(CTX, Node, gossipMsgByte)
+}
+
+// PodVoteRequestMsgHandler sends the vote of this track on the current pod
+// to the track that asked for it.
+func PodVoteRequestMsgHandler(dataByte []byte, from peer.ID) {
+	var request PodVoteRequestMsgData
+	if err := json.Unmarshal(dataByte, &request); err != nil {
+		scores.penalize(from, penaltyDecodeError, err)
+		return
+	}
+	vote := ownPodVote()
+	if vote.PodNumber != request.PodNumber {
+		return
+	}
+	voteByte, err := json.Marshal(vote)
+	if err != nil {
+		logs.Log.Error("Error in Marshaling pod vote")
+		return
+	}
+	signed, err := authenticator.sign("podVote", voteByte)
+	if err != nil {
+		logs.Log.Error(fmt.Sprintf("Error signing podVote: %s", err))
+		return
+	}
+	if err = sendMessage(CTX, Node, from, "podVote", signed); err != nil {
+		logs.Log.Warn(fmt.Sprintf("Error sending podVote to %s: %s", from, err))
+	}
+}
+
+// recordPodVote adds vote to the votes of the current pod and saves them
+// with the pod state, so a restarted track keeps them. The first vote of a
+// track counts; a track voting twice for different hashes is logged. A vote
+// for another TracksAppHash is saved as a disagreement.
+func recordPodVote(vote PodVoteMsgData, from peer.ID) {
+	var (
+		recorded, agree bool
+		own             []byte
+	)
+	shared.UpdatePodState(func(podState *shared.PodState) {
+		if podState.LatestPodHeight != vote.PodNumber {
+			return
+		}
+		if previous, ok := podState.Votes[vote.Voter]; ok {
+			if !bytes.Equal(previous.TracksAppHash, vote.TracksAppHash) {
+				logs.Log.Warn(fmt.Sprintf("Track %s changed its vote on pod %d, keeping the first one", vote.Voter, vote.PodNumber))
+			}
+			return
+		}
+
+		own = podState.TracksAppHash
+		agree = bytes.Equal(vote.TracksAppHash, own)
+		// readers of the pod state range over the map without the lock:
+		// replace it rather than writing to it
+		votes := make(map[string]shared.Votes, len(podState.Votes)+1)
+		for voter, v := range podState.Votes {
+			votes[voter] = v
+		}
+		votes[vote.Voter] = shared.Votes{
+			PeerID:        from.String(),
+			Vote:          agree,
+			Address:       vote.Voter,
+			TracksAppHash: vote.TracksAppHash,
+		}
+		podState.Votes = votes
+		updatePodStateInDatabase(podState)
+		recorded = true
+	})
+
+	if recorded && !agree {
+		logs.Log.Warn(fmt.Sprintf("Track %s voted for tracks app hash %x on pod %d, this track derived %x", vote.Voter, vote.TracksAppHash, vote.PodNumber, own))
+		saveDisagreement(PodDisagreement{
+			PodNumber:        vote.PodNumber,
+			Voter:            vote.Voter,
+			PeerID:           from.String(),
+			TracksAppHash:    vote.TracksAppHash,
+			OwnTracksAppHash: own,
+			Time:             time.Now(),
+		})
+	}
+}
+
+func saveDisagreement(d PodDisagreement) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		logs.Log.Error("Error in Marshaling pod disagreement")
+		return
+	}
+	key := fmt.Sprintf("disagreement-%d-%s", d.PodNumber, d.Voter)
+	if err = shared.Node.Store.Disagreements().Put([]byte(key), data); err != nil {
+		logs.Log.Error(fmt.Sprintf("Error saving the disagreement of %s on pod %d: %v", d.Voter, d.PodNumber, err))
+	}
+}
+
+// quorumSize is how many of tracks votes make percent of them, rounded up.
+func quorumSize(tracks int, percent int) int {
+	needed := (tracks*percent + 99) / 100
+	if needed < 1 {
+		needed = 1
+	}
+	return needed
+}
+
+// agreeingVotes counts the votes on podNumber for the TracksAppHash of this
+// track, its own included.
+func agreeingVotes(podNumber uint64) int {
+	agreeing := 0
+	shared.ViewPodState(func(podState *shared.PodState) {
+		if podState.LatestPodHeight != podNumber {
+			return
+		}
+		for _, v := range podState.Votes {
+			if v.Vote {
+				agreeing++
+			}
+		}
+	})
+	return agreeing
+}
+
+// waitForQuorum waits until consensus.quorum_percent of the tracks voted for
+// the TracksAppHash of this track on podNumber. It gives up after half the
+// propose timeout, leaving the leader time to initiate the VRF within its
+// round. The tracks are asked for their votes again once, when they are
+// missing at first, as a restarted leader lost those sent before.
+func waitForQuorum(podNumber uint64) error {
+	baseConfig, err := config.Current()
+	if err != nil {
+		return fmt.Errorf("error loading config: %v", err)
+	}
+	consensus := baseConfig.Consensus
+	if !consensus.ValidatePods {
+		return nil
+	}
+	if authenticator == nil {
+		return fmt.Errorf("the junction account is not loaded")
+	}
+	needed := quorumSize(len(authenticator.identity.tracks), consensus.QuorumPercent)
+	if agreeingVotes(podNumber) < needed {
+		requestPodVotes(podNumber)
+	}
+	deadline := time.Now().Add(consensus.TimeoutPropose / 2)
+	for {
+		agreeing := agreeingVotes(podNumber)
+		if agreeing >= needed {
+			logs.Log.Info(fmt.Sprintf("Pod %d has %d of the %d votes needed", podNumber, agreeing, needed))
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("pod %d has %d of the %d votes needed", podNumber, agreeing, needed)
+		}
+		time.Sleep(consensus.PodValidationSleepDuration)
+	}
+}
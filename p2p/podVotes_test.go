@@ -0,0 +1,69 @@
+package p2p
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/airchains-network/decentralized-sequencer/node/shared"
+	"github.com/airchains-network/decentralized-sequencer/store"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestPodVotesReachQuorum(t *testing.T) {
+	for _, c := range []struct{ tracks, percent, want int }{
+		{1, 66, 1}, {3, 66, 2}, {4, 66, 3}, {3, 100, 3}, {5, 51, 3}, {2, 1, 1},
+	} {
+		if got := quorumSize(c.tracks, c.percent); got != c.want {
+			t.Errorf("quorumSize(%d, %d) = %d, want %d", c.tracks, c.percent, got, c.want)
+		}
+	}
+
+	s, err := store.Open(store.GoLevelDBBackend, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	previous := shared.Node
+	defer func() { shared.Node = previous }()
+	shared.Node = &shared.NodeS{Store: s}
+	own := []byte("own hash")
+	shared.SetPodState(&shared.PodState{LatestPodHeight: 7, TracksAppHash: own, Votes: map[string]shared.Votes{}})
+
+	recordPodVote(PodVoteMsgData{PodNumber: 7, Voter: "air1self", TracksAppHash: own}, "self")
+	recordPodVote(PodVoteMsgData{PodNumber: 7, Voter: "air1honest", TracksAppHash: own}, "honest")
+	recordPodVote(PodVoteMsgData{PodNumber: 7, Voter: "air1fork", TracksAppHash: []byte("other hash")}, "fork")
+	// a second vote of a track and a vote of another pod do not count
+	recordPodVote(PodVoteMsgData{PodNumber: 7, Voter: "air1fork", TracksAppHash: own}, "fork")
+	recordPodVote(PodVoteMsgData{PodNumber: 8, Voter: "air1late", TracksAppHash: own}, "late")
+
+	if got := len(shared.GetPodState().Votes); got != 3 {
+		t.Fatalf("%d votes recorded, want 3", got)
+	}
+	if got := agreeingVotes(7); got != 2 {
+		t.Fatalf("%d agreeing votes, want 2", got)
+	}
+
+	data, err := s.Disagreements().Get([]byte("disagreement-7-air1fork"))
+	if err != nil {
+		t.Fatalf("disagreement not saved: %v", err)
+	}
+	var d PodDisagreement
+	if err = json.Unmarshal(data, &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.PeerID != peer.ID("fork").String() || string(d.TracksAppHash) != "other hash" || string(d.OwnTracksAppHash) != string(own) {
+		t.Fatalf("saved disagreement %+v", d)
+	}
+	if has, _ := s.Disagreements().Has([]byte("disagreement-7-air1honest")); has {
+		t.Fatal("agreeing vote saved as a disagreement")
+	}
+
+	// the votes are saved with the pod state for a restarted track
+	saved, err := GetPodStateFromDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Votes) != 3 || !saved.Votes["air1honest"].Vote || saved.Votes["air1fork"].Vote {
+		t.Fatalf("saved votes %+v", saved.Votes)
+	}
+}
@@ -0,0 +1,286 @@
+package p2p
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/junction"
+	"github.com/airchains-network/decentralized-sequencer/node/shared"
+	"sync"
+)
+
+// maxPodLookahead is how far ahead of the local pod a message may be. A
+// track that is one pod ahead starts the VRF of the next pod before the
+// others saved the current one.
+const maxPodLookahead = 1
+
+var (
+	// errReplayedMessage is returned for a message that was already accepted.
+	errReplayedMessage = errors.New("message was already received")
+	errUnsignedMessage = errors.New("unsigned message")
+	errWrongPod        = errors.New("wrong pod number")
+	errNoPodState      = errors.New("pod state is not loaded")
+	errWrongSender     = errors.New("wrong sender")
+)
+
+// signedMessage is the payload of every message between tracks. It is
+// signed with the junction key of the sender, whose address must be in the
+// Tracks list of the station.
+type signedMessage struct {
+	StationId string `json:"stationId"`
+	Type      string `json:"type"`
+	PodNumber uint64 `json:"podNumber"`
+	Sender    string `json:"sender"`
+	Data      []byte `json:"data"`
+	PubKey    []byte `json:"pubKey"`
+	Signature []byte `json:"signature"`
+}
+
+func (m *signedMessage) signBytes() []byte {
+	header := fmt.Sprintf("tracks-gossip/%s/%s/%d/%s/", m.StationId, m.Type, m.PodNumber, m.Sender)
+	return append([]byte(header), m.Data...)
+}
+
+// messageAuth signs the messages of this track and authenticates those of
+// the others.
+type messageAuth struct {
+	identity *trackIdentity
+	// podHeight is the number of the pod this track is at
+	podHeight func() (uint64, error)
+
+	mu sync.Mutex
+	// seen holds the digests of accepted messages with their pod number
+	seen map[[sha256.Size]byte]uint64
+}
+
+var authenticator *messageAuth
+
+func newMessageAuth(identity *trackIdentity, podHeight func() (uint64, error)) *messageAuth {
+	return &messageAuth{
+		identity:  identity,
+		podHeight: podHeight,
+		seen:      make(map[[sha256.Size]byte]uint64),
+	}
+}
+
+func currentPodHeight() (uint64, error) {
+	podState := shared.GetPodState()
+	if podState == nil {
+		return 0, errNoPodState
+	}
+	return podState.LatestPodHeight, nil
+}
+
+// messageChecks bind the content of a message type to its sender, once the
+// signature is verified.
+var messageChecks = map[string]func(sender string, data []byte, tracks []string) error{
+	"vrfInitiated": func(sender string, data []byte, tracks []string) error {
+		var msg VRFInitiatedMsgData
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		if msg.VrfInitiatorAddress != sender {
+			return fmt.Errorf("VRF initiated by %s is sent by %s", msg.VrfInitiatorAddress, sender)
+		}
+		return checkSelectedTrack(msg.SelectedTrackAddress, sender, tracks)
+	},
+	"vrnValidated": func(sender string, data []byte, tracks []string) error {
+		var msg VRFVerifiedMsg
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		if !isTrack(msg.SelectedTrackAddress, tracks) {
+			return fmt.Errorf("selected %s is not a track of the station", msg.SelectedTrackAddress)
+		}
+		return nil
+	},
+	"podSubmitted": func(sender string, data []byte, tracks []string) error {
+		var msg PodSubmittedMsgData
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		return checkSelectedTrack(msg.SelectedTrackAddress, sender, tracks)
+	},
+	"podVote": func(sender string, data []byte, tracks []string) error {
+		var msg PodVoteMsgData
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		if msg.Voter != sender {
+			return fmt.Errorf("vote of %s is sent by %s", msg.Voter, sender)
+		}
+		return nil
+	},
+	"podVerified": func(sender string, data []byte, tracks []string) error {
+		var msg PodVerifiedMsgData
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		if msg.VerificationResult && msg.PodVerifiedTxHash == "" {
+			return fmt.Errorf("pod verified without a verification transaction")
+		}
+		return nil
+	},
+	"vrnVote": func(sender string, data []byte, tracks []string) error {
+		var msg VrnVoteMsgData
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		if msg.Voter != sender {
+			return fmt.Errorf("vote of %s is sent by %s", msg.Voter, sender)
+		}
+		return nil
+	},
+}
+
+// checkSelectedTrack checks a track selected by sender to verify its work.
+func checkSelectedTrack(selected string, sender string, tracks []string) error {
+	if !isTrack(selected, tracks) {
+		return fmt.Errorf("selected %s is not a track of the station", selected)
+	}
+	if selected == sender {
+		return fmt.Errorf("%s selected itself", sender)
+	}
+	return nil
+}
+
+// podNumberOf reads the pod number every gossip message type carries.
+func podNumberOf(data []byte) (uint64, error) {
+	var msg struct{ PodNumber uint64 }
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return 0, fmt.Errorf("invalid message: %v", err)
+	}
+	return msg.PodNumber, nil
+}
+
+// sign wraps the message data of dataType into a signedMessage.
+func (a *messageAuth) sign(dataType string, data []byte) ([]byte, error) {
+	podNumber, err := podNumberOf(data)
+	if err != nil {
+		return nil, err
+	}
+	msg := &signedMessage{
+		StationId: a.identity.stationId,
+		Type:      dataType,
+		PodNumber: podNumber,
+		Sender:    a.identity.signer.Address(),
+		Data:      data,
+	}
+	if msg.PubKey, msg.Signature, err = a.identity.signer.Sign(msg.signBytes()); err != nil {
+		return nil, err
+	}
+	return json.Marshal(msg)
+}
+
+// open authenticates a signedMessage received as dataType and returns its
+// sender with the message data. Each message is accepted once.
+func (a *messageAuth) open(dataType string, payload []byte) (string, []byte, error) {
+	var msg signedMessage
+	if err := json.Unmarshal(payload, &msg); err != nil || len(msg.Signature) == 0 {
+		return "", nil, errUnsignedMessage
+	}
+	if msg.Type != dataType {
+		return "", nil, fmt.Errorf("%s message is signed as %s", dataType, msg.Type)
+	}
+	if msg.StationId != a.identity.stationId {
+		return "", nil, fmt.Errorf("message is signed for station %q", msg.StationId)
+	}
+	if !isTrack(msg.Sender, a.identity.tracks) {
+		return "", nil, fmt.Errorf("sender %s is not a track of the station", msg.Sender)
+	}
+	if err := junction.VerifySignature(msg.Sender, a.identity.addressPrefix, msg.PubKey, msg.signBytes(), msg.Signature); err != nil {
+		return "", nil, err
+	}
+
+	podNumber, err := podNumberOf(msg.Data)
+	if err != nil {
+		return "", nil, err
+	}
+	if podNumber != msg.PodNumber {
+		return "", nil, fmt.Errorf("message of pod %d is signed for pod %d", podNumber, msg.PodNumber)
+	}
+	height, err := a.podHeight()
+	if err != nil {
+		return "", nil, err
+	}
+	if podNumber < height || podNumber > height+maxPodLookahead {
+		return "", nil, fmt.Errorf("%w: message of pod %d, this track is at pod %d", errWrongPod, podNumber, height)
+	}
+	if check, ok := messageChecks[dataType]; ok {
+		if err = check(msg.Sender, msg.Data, a.identity.tracks); err != nil {
+			return "", nil, err
+		}
+	}
+
+	digest := sha256.Sum256(msg.signBytes())
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for d, pod := range a.seen {
+		if pod < height {
+			delete(a.seen, d)
+		}
+	}
+	if _, ok := a.seen[digest]; ok {
+		return "", nil, errReplayedMessage
+	}
+	a.seen[digest] = podNumber
+	return msg.Sender, msg.Data, nil
+}
+
+// nextPodStep is the step whose sender a step message selects.
+var nextPodStep = map[string]string{
+	"vrfInitiated": "vrnValidated",
+	"vrnValidated": "podSubmitted",
+	"podSubmitted": "podVerified",
+}
+
+// vrfInitiatorStep is the StepSenders key of the initiator of the VRF, who
+// announces the VRN itself after a dispute.
+const vrfInitiatorStep = "vrfInitiator"
+
+// checkStepSender rejects a step message of the current pod sent by another
+// track than the one the step before selected for it.
+func checkStepSender(dataType string, sender string) error {
+	podState := shared.GetPodState()
+	if podState == nil {
+		return nil
+	}
+	expected, ok := podState.StepSenders[dataType]
+	if !ok || sender == expected {
+		return nil
+	}
+	if dataType == "vrnValidated" && sender == podState.StepSenders[vrfInitiatorStep] {
+		return nil
+	}
+	return fmt.Errorf("%w: %s of pod %d is sent by %s, not %s", errWrongSender, dataType, podState.LatestPodHeight, sender, expected)
+}
+
+// recordStepSender records the track the step message dataType of pod
+// podNumber, sent by sender, selected to send the next step.
+func recordStepSender(podNumber uint64, dataType string, sender string, data []byte) {
+	next, ok := nextPodStep[dataType]
+	if !ok {
+		return
+	}
+	var msg struct {
+		SelectedTrackAddress string
+	}
+	if err := json.Unmarshal(data, &msg); err != nil || msg.SelectedTrackAddress == "" {
+		return
+	}
+	shared.UpdatePodState(func(podState *shared.PodState) {
+		if podState.LatestPodHeight != podNumber {
+			return
+		}
+		senders := make(map[string]string, len(podState.StepSenders)+2)
+		for step, s := range podState.StepSenders {
+			senders[step] = s
+		}
+		if dataType == "vrfInitiated" {
+			senders[vrfInitiatorStep] = sender
+		}
+		senders[next] = msg.SelectedTrackAddress
+		podState.StepSenders = senders
+	})
+}
@@ -0,0 +1,126 @@
+package p2p
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/airchains-network/decentralized-sequencer/junction"
+	"github.com/airchains-network/decentralized-sequencer/node/shared"
+)
+
+func testAuth(signer *junction.Signer, tracks []string, podHeight uint64) *messageAuth {
+	identity := &trackIdentity{signer: signer, stationId: "station", addressPrefix: "air", tracks: tracks}
+	return newMessageAuth(identity, func() (uint64, error) { return podHeight, nil })
+}
+
+func TestSignedMessagesAreBoundToTracks(t *testing.T) {
+	track, other, outsider := testSigner(t), testSigner(t), testSigner(t)
+	tracks := []string{track.Address(), other.Address()}
+	vrfInitiated := func(podNumber uint64, initiator string, selected string) []byte {
+		return []byte(fmt.Sprintf(`{"PodNumber":%d,"SelectedTrackAddress":%q,"VrfInitiatorAddress":%q}`, podNumber, selected, initiator))
+	}
+	sign := func(signer *junction.Signer, dataType string, data []byte) []byte {
+		signed, err := testAuth(signer, tracks, 5).sign(dataType, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	valid := sign(track, "vrfInitiated", vrfInitiated(5, track.Address(), other.Address()))
+	tampered := func(edit func(m *signedMessage)) []byte {
+		var m signedMessage
+		if err := json.Unmarshal(valid, &m); err != nil {
+			t.Fatal(err)
+		}
+		edit(&m)
+		data, _ := json.Marshal(&m)
+		return data
+	}
+
+	receiver := testAuth(other, tracks, 5)
+	for _, tc := range []struct {
+		name     string
+		dataType string
+		payload  []byte
+		ok       bool
+	}{
+		{"signed by a track", "vrfInitiated", valid, true},
+		{"replayed", "vrfInitiated", valid, false},
+		{"next pod", "podVerified", sign(track, "podVerified", []byte(`{"PodNumber":6}`)), true},
+		{"unsigned", "vrfInitiated", vrfInitiated(5, track.Address(), other.Address()), false},
+		{"not a track", "podVerified", sign(outsider, "podVerified", []byte(`{"PodNumber":5}`)), false},
+		{"sent as another type", "podVerified", valid, false},
+		{"data changed", "vrfInitiated", tampered(func(m *signedMessage) {
+			m.Data = vrfInitiated(5, track.Address(), track.Address())
+		}), false},
+		{"sender changed", "vrfInitiated", tampered(func(m *signedMessage) { m.Sender = other.Address() }), false},
+		{"other station", "vrfInitiated", tampered(func(m *signedMessage) { m.StationId = "other" }), false},
+		{"pod number changed", "vrfInitiated", tampered(func(m *signedMessage) { m.PodNumber = 6 }), false},
+		{"stale pod", "podVerified", sign(track, "podVerified", []byte(`{"PodNumber":4}`)), false},
+		{"pod too far ahead", "podVerified", sign(track, "podVerified", []byte(`{"PodNumber":7}`)), false},
+		{"initiated by another track", "vrfInitiated", sign(track, "vrfInitiated", vrfInitiated(5, other.Address(), other.Address())), false},
+		{"selected an outsider", "podSubmitted", sign(track, "podSubmitted", []byte(fmt.Sprintf(`{"PodNumber":5,"SelectedTrackAddress":%q}`, outsider.Address()))), false},
+	} {
+		sender, _, err := receiver.open(tc.dataType, tc.payload)
+		if tc.ok && (err != nil || sender != track.Address()) {
+			t.Errorf("%s: got sender %q, %v", tc.name, sender, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("%s: accepted", tc.name)
+		}
+	}
+}
+
+func TestPodStepsComeFromSelectedTracks(t *testing.T) {
+	previous := shared.Node
+	defer func() { shared.Node = previous }()
+	shared.Node = &shared.NodeS{}
+	shared.SetPodState(&shared.PodState{LatestPodHeight: 3})
+
+	step := func(dataType, sender, selected string) error {
+		if err := checkStepSender(dataType, sender); err != nil {
+			return err
+		}
+		data, _ := json.Marshal(map[string]interface{}{"PodNumber": 3, "SelectedTrackAddress": selected})
+		recordStepSender(3, dataType, sender, data)
+		return nil
+	}
+	if err := step("vrfInitiated", "air1leader", "air1verifier"); err != nil {
+		t.Fatal(err)
+	}
+	if err := step("vrnValidated", "air1other", "air1submitter"); err == nil {
+		t.Fatal("accepted the VRN from a track the VRF did not select")
+	}
+	// after a dispute the initiator of the VRF announces the VRN
+	if err := step("vrnValidated", "air1leader", "air1submitter"); err != nil {
+		t.Fatal(err)
+	}
+	if err := step("podSubmitted", "air1verifier", "air1prover"); err == nil {
+		t.Fatal("accepted the pod from a track the VRN did not select")
+	}
+	if err := step("podSubmitted", "air1submitter", "air1prover"); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkStepSender("podVerified", "air1submitter"); err == nil {
+		t.Fatal("accepted the verified pod from a track the pod did not select")
+	}
+	if err := checkStepSender("podVerified", "air1prover"); err != nil {
+		t.Fatal(err)
+	}
+	// a message of another pod does not change the senders of this one
+	recordStepSender(4, "vrfInitiated", "air1other", []byte(`{"SelectedTrackAddress":"air1other"}`))
+	if err := checkStepSender("vrnValidated", "air1other"); err == nil {
+		t.Fatal("a message of pod 4 changed the senders of pod 3")
+	}
+
+	for data, ok := range map[string]bool{
+		`{"PodNumber":3,"VerificationResult":true,"PodVerifiedTxHash":"ABC"}`: true,
+		`{"PodNumber":3,"VerificationResult":true}`:                           false,
+		`{"PodNumber":3,"VerificationResult":false}`:                          true,
+	} {
+		if err := messageChecks["podVerified"]("air1prover", []byte(data), nil); (err == nil) != ok {
+			t.Errorf("podVerified %s: %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package p2p
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/airchains-network/decentralized-sequencer/junction"
+	junctionTypes "github.com/airchains-network/decentralized-sequencer/junction/types"
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/airchains-network/decentralized-sequencer/node/shared"
+	"github.com/airchains-network/decentralized-sequencer/types"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"sort"
+	"sync"
+	"time"
+)
+
+// vrfDispute holds the votes on the VRN of a pod the junction failed to
+// verify. Every track votes once; the track that initiated the VRF collects
+// the votes and submits them with MsgProcessVrfDispute.
+type vrfDispute struct {
+	podNumber uint64
+	voted     bool
+	votes     map[string]VrnVoteMsgData
+}
+
+var (
+	disputeMu sync.Mutex
+	dispute   *vrfDispute
+)
+
+// currentDispute returns the dispute of podNumber, starting it if needed.
+// It is called with disputeMu held.
+func currentDispute(podNumber uint64) *vrfDispute {
+	if dispute == nil || dispute.podNumber != podNumber {
+		dispute = &vrfDispute{podNumber: podNumber, votes: make(map[string]VrnVoteMsgData)}
+	}
+	return dispute
+}
+
+// disputeVRF votes on the VRN of vrfRecord, which the junction failed to
+// verify. On the track that initiated the VRF, it then collects the votes of
+// the tracks, submits them and returns the result; other tracks, and later
+// calls for the same pod, return a nil result.
+func disputeVRF(vrfRecord *junctionTypes.VrfRecord) (*junctionTypes.VrfDisputeResult, error) {
+	if authenticator == nil {
+		return nil, fmt.Errorf("can not vote on the VRN, the junction account is not loaded")
+	}
+	podNumber := shared.GetPodState().LatestPodHeight
+	cast, err := castVrnVote(podNumber, vrfRecord)
+	if err != nil || !cast {
+		return nil, err
+	}
+	if vrfRecord.VrfCreatorAddr != authenticator.identity.signer.Address() {
+		return nil, nil
+	}
+	return resolveVrfDispute(podNumber)
+}
+
+// castVrnVote checks the VRF proof of vrfRecord and sends the signed result
+// to the other tracks. It reports false when this track already voted on
+// the pod.
+func castVrnVote(podNumber uint64, vrfRecord *junctionTypes.VrfRecord) (bool, error) {
+	disputeMu.Lock()
+	d := currentDispute(podNumber)
+	voted := d.voted
+	d.voted = true
+	disputeMu.Unlock()
+	if voted {
+		return false, nil
+	}
+
+	identity := authenticator.identity
+	vote := true
+	if err := junction.CheckVrfRecord(vrfRecord, uint64(len(identity.tracks))); err != nil {
+		logs.Log.Warn(fmt.Sprintf("Voting against the VRN of pod %d: %v", podNumber, err))
+		vote = false
+	}
+	pubKey, signature, err := identity.signer.Sign(junction.VrfDisputeVoteBytes(identity.stationId, podNumber, vote))
+	if err != nil {
+		return false, fmt.Errorf("error signing the VRN vote: %v", err)
+	}
+	msg := VrnVoteMsgData{
+		PodNumber: podNumber,
+		Voter:     identity.signer.Address(),
+		Vote:      vote,
+		PubKey:    pubKey,
+		Signature: signature,
+	}
+	recordVrnVote(msg)
+
+	msgByte, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("error in Marshaling VRN vote: %v", err)
+	}
+	gossipMsgByte, err := json.Marshal(types.GossipData{Type: "vrnVote", Data: msgByte})
+	if err != nil {
+		return false, fmt.Errorf("%s: %v", LogMarshalGossipMsg, err)
+	}
+	BroadcastMessage(CTX, Node, gossipMsgByte)
+	return true, nil
+}
+
+// resetVrfDispute drops the votes on the VRN of podNumber, for a new VRF of
+// the pod to be voted on.
+func resetVrfDispute(podNumber uint64) {
+	disputeMu.Lock()
+	defer disputeMu.Unlock()
+	if dispute != nil && dispute.podNumber == podNumber {
+		dispute = nil
+	}
+}
+
+func recordVrnVote(vote VrnVoteMsgData) {
+	disputeMu.Lock()
+	defer disputeMu.Unlock()
+	d := currentDispute(vote.PodNumber)
+	if _, ok := d.votes[vote.Voter]; !ok {
+		d.votes[vote.Voter] = vote
+	}
+}
+
+// VrnVoteMsgHandler records the VRN vote of another track. The first vote
+// of a dispute makes this track vote too, and the initiator of the VRF
+// resolve it.
+func VrnVoteMsgHandler(dataByte []byte, from peer.ID) {
+	var vote VrnVoteMsgData
+	if err := json.Unmarshal(dataByte, &vote); err != nil {
+		scores.penalize(from, penaltyDecodeError, err)
+		return
+	}
+	identity := authenticator.identity
+	voteBytes := junction.VrfDisputeVoteBytes(identity.stationId, vote.PodNumber, vote.Vote)
+	if err := junction.VerifySignature(vote.Voter, identity.addressPrefix, vote.PubKey, voteBytes, vote.Signature); err != nil {
+		scores.penalize(from, penaltyInvalidMessage, fmt.Errorf("VRN vote: %v", err))
+		return
+	}
+	recordVrnVote(vote)
+
+	disputeMu.Lock()
+	voted := currentDispute(vote.PodNumber).voted
+	disputeMu.Unlock()
+	if !voted {
+		go joinVrfDispute()
+	}
+}
+
+// joinVrfDispute votes on a dispute started by another track, and resumes
+// the pod from its result on the initiator of the VRF.
+func joinVrfDispute() {
+	vrfRecord := junction.QueryVRF()
+	if vrfRecord == nil {
+		logs.Log.Error("VRF record is nil")
+		return
+	}
+	result, err := disputeVRF(vrfRecord)
+	if err != nil {
+		logs.Log.Error(err.Error())
+		return
+	}
+	if result == nil {
+		return
+	}
+	podNumber := shared.GetPodState().LatestPodHeight
+	if !result.Result {
+		logs.Log.Warn(fmt.Sprintf("The junction rejected the VRN of pod %d, the next round starts over", podNumber))
+		return
+	}
+	ad, err := getAccountDetails()
+	if err != nil {
+		logs.Log.Error(err.Error())
+		return
+	}
+	logs.Log.Info(fmt.Sprintf("The junction upheld the VRN of pod %d", podNumber))
+	announceValidatedVRN(vrfRecord, ad)
+}
+
+// resolveVrfDispute waits for the votes of every track, or until half the
+// prevote timeout passed, then submits them if they make a quorum. The
+// result holds the votes and whether the junction then verified the VRF; it
+// is recorded in the pod state.
+func resolveVrfDispute(podNumber uint64) (*junctionTypes.VrfDisputeResult, error) {
+	baseConfig, err := config.Current()
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %v", err)
+	}
+	consensus := baseConfig.Consensus
+	tracks := len(authenticator.identity.tracks)
+
+	deadline := time.Now().Add(consensus.TimeoutPrevote / 2)
+	var votes []VrnVoteMsgData
+	for {
+		votes = disputeVotes(podNumber)
+		if len(votes) == tracks || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(consensus.PodValidationSleepDuration)
+	}
+	if needed := quorumSize(tracks, consensus.QuorumPercent); len(votes) < needed {
+		return nil, fmt.Errorf("VRN of pod %d got %d of the %d votes needed for a dispute", podNumber, len(votes), needed)
+	}
+
+	signatures := make([][]byte, len(votes))
+	voteValues := make([]bool, len(votes))
+	publicKeys := make([][]byte, len(votes))
+	for i, v := range votes {
+		signatures[i], voteValues[i], publicKeys[i] = v.Signature, v.Vote, v.PubKey
+	}
+	if !junction.ProcessVrfDispute(signatures, voteValues, publicKeys) {
+		return nil, fmt.Errorf("failed to process the VRF dispute of pod %d", podNumber)
+	}
+
+	// the junction decides the dispute, the tally only records the votes
+	vrfRecord := junction.QueryVRF()
+	if vrfRecord == nil {
+		return nil, fmt.Errorf("can not query the VRF of pod %d after its dispute", podNumber)
+	}
+	result := tallyVrnVotes(votes, consensus.QuorumPercent)
+	if result.Result != vrfRecord.IsVerified {
+		logs.Log.Warn(fmt.Sprintf("The junction decided the VRF dispute of pod %d against the tally of the tracks", podNumber))
+	}
+	result.Result = vrfRecord.IsVerified
+	if result.Result {
+		result.Message += ", the junction upheld it"
+	} else {
+		result.Message += ", the junction rejected it"
+	}
+	shared.UpdatePodState(func(podState *shared.PodState) {
+		podState.VrfDisputeResult = result
+		updatePodStateInDatabase(podState)
+	})
+	logs.Log.Info(fmt.Sprintf("VRF dispute of pod %d: %s", podNumber, result.Message))
+	return result, nil
+}
+
+// disputeVotes returns the votes on the VRN of podNumber, by voter.
+func disputeVotes(podNumber uint64) []VrnVoteMsgData {
+	disputeMu.Lock()
+	defer disputeMu.Unlock()
+	d := currentDispute(podNumber)
+	votes := make([]VrnVoteMsgData, 0, len(d.votes))
+	for _, v := range d.votes {
+		votes = append(votes, v)
+	}
+	sort.Slice(votes, func(i, j int) bool {
+		return votes[i].Voter < votes[j].Voter
+	})
+	return votes
+}
+
+// tallyVrnVotes upholds the VRN when quorumPercent of the votes are for it.
+func tallyVrnVotes(votes []VrnVoteMsgData, quorumPercent int) *junctionTypes.VrfDisputeResult {
+	result := &junctionTypes.VrfDisputeResult{}
+	for _, v := range votes {
+		result.Votes = append(result.Votes, v.Vote)
+		result.AddressList = append(result.AddressList, v.Voter)
+		if v.Vote {
+			result.ConsentVote++
+		} else {
+			result.DissentVote++
+		}
+	}
+	if len(votes) > 0 {
+		result.AgreementPercentage = float32(result.ConsentVote) * 100 / float32(len(votes))
+	}
+	result.Result = result.AgreementPercentage >= float32(quorumPercent)
+	result.Message = fmt.Sprintf("%d of %d tracks vouch for the VRN", result.ConsentVote, len(votes))
+	return result
+}
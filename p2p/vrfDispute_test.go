@@ -0,0 +1,77 @@
+package p2p
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/airchains-network/decentralized-sequencer/junction"
+	junctionTypes "github.com/airchains-network/decentralized-sequencer/junction/types"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+)
+
+func TestVrfDisputeVotes(t *testing.T) {
+	// a VRF made as InitVRF makes it checks, a tampered one does not
+	privateKey, publicKey := junction.NewKeyPair()
+	rc := []byte("request commitment")
+	proof, output, err := junction.GenerateVRFProof(edwards25519.NewBlakeSHA256Ed25519(), privateKey, rc, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := &junctionTypes.VrfRecord{CreatorsVrfKey: publicKey.String(), SerializedRcFromCreator: rc, Proof: proof, VrfOutput: output, SelectedTrackIndex: 1}
+	if err = junction.CheckVrfRecord(record, 3); err != nil {
+		t.Fatalf("valid VRF refused: %v", err)
+	}
+	record.SerializedRcFromCreator = []byte("another request commitment")
+	if err = junction.CheckVrfRecord(record, 3); err == nil {
+		t.Fatal("VRF of another request commitment accepted")
+	}
+
+	self, other := testSigner(t), testSigner(t)
+	previous := authenticator
+	defer func() { authenticator = previous }()
+	authenticator = testAuth(self, []string{self.Address(), other.Address()}, 7)
+	disputeMu.Lock()
+	// this track already voted: the handler only records
+	dispute = &vrfDispute{podNumber: 7, voted: true, votes: map[string]VrnVoteMsgData{}}
+	disputeMu.Unlock()
+
+	vote := func(voted bool, signed bool) []byte {
+		pubKey, signature, err := other.Sign(junction.VrfDisputeVoteBytes("station", 7, signed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := json.Marshal(VrnVoteMsgData{PodNumber: 7, Voter: other.Address(), Vote: voted, PubKey: pubKey, Signature: signature})
+		return data
+	}
+	VrnVoteMsgHandler(vote(true, false), "other")
+	if votes := disputeVotes(7); len(votes) != 0 {
+		t.Fatalf("vote signed for another value recorded: %+v", votes)
+	}
+	VrnVoteMsgHandler(vote(true, true), "other")
+	VrnVoteMsgHandler(vote(false, false), "other")
+	votes := disputeVotes(7)
+	if len(votes) != 1 || !votes[0].Vote {
+		t.Fatalf("votes %+v, want the first vote of other", votes)
+	}
+
+	// a new VRF of the pod is voted on from scratch
+	resetVrfDispute(8)
+	if len(disputeVotes(7)) != 1 {
+		t.Fatal("reset of pod 8 dropped the votes of pod 7")
+	}
+	resetVrfDispute(7)
+	disputeMu.Lock()
+	voted := currentDispute(7).voted
+	disputeMu.Unlock()
+	if voted || len(disputeVotes(7)) != 0 {
+		t.Fatal("votes on the rejected VRN kept")
+	}
+
+	tally := []VrnVoteMsgData{{Voter: "a", Vote: true}, {Voter: "b", Vote: true}, {Voter: "c", Vote: false}}
+	if result := tallyVrnVotes(tally, 66); !result.Result || result.ConsentVote != 2 || result.DissentVote != 1 {
+		t.Fatalf("2 of 3 votes for the VRN at 66%%: %+v", result)
+	}
+	if result := tallyVrnVotes(tally, 67); result.Result {
+		t.Fatalf("2 of 3 votes for the VRN at 67%%: %+v", result)
+	}
+}
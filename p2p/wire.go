@@ -0,0 +1,242 @@
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"io"
+	"sync"
+	"time"
+)
+
+// Messages between tracks are sent on wireProtocolID as frames:
+//
+//	| version (1 byte) | type (1 byte) | length (4 bytes, big endian) | payload |
+//
+// A stream carries any number of frames and is kept open for the next
+// messages to the same peer. Tracks that only speak customProtocolID send one
+// JSON types.GossipData per stream.
+const (
+	wireProtocolID  = "/station/tracks/1.0.0"
+	wireVersion     = 1
+	frameHeaderSize = 6
+	// MaxMessageSize bounds the payload of a frame, large enough for a pod
+	// with its proof
+	MaxMessageSize = 8 << 20
+
+	writeTimeout = 30 * time.Second
+)
+
+// messageTypes are the gossip types and their code on the wire. New types
+// are appended, codes are never reused.
+var messageTypes = []string{
+	1: "vrfInitiated",
+	2: "vrnValidated",
+	3: "podSubmitted",
+	4: "podVerified",
+	5: "podVote",
+	6: "vrnVote",
+	7: "podVoteRequest",
+}
+
+func messageTypeCode(dataType string) (byte, bool) {
+	for code, name := range messageTypes {
+		if name != "" && name == dataType {
+			return byte(code), true
+		}
+	}
+	return 0, false
+}
+
+// encodeFrame returns the frame of a message of dataType.
+func encodeFrame(dataType string, data []byte) ([]byte, error) {
+	code, ok := messageTypeCode(dataType)
+	if !ok {
+		return nil, fmt.Errorf("unknown message type %q", dataType)
+	}
+	if len(data) > MaxMessageSize {
+		return nil, fmt.Errorf("%s message of %d bytes exceeds the limit of %d", dataType, len(data), MaxMessageSize)
+	}
+	frame := make([]byte, frameHeaderSize+len(data))
+	frame[0] = wireVersion
+	frame[1] = code
+	binary.BigEndian.PutUint32(frame[2:frameHeaderSize], uint32(len(data)))
+	copy(frame[frameHeaderSize:], data)
+	return frame, nil
+}
+
+// writeFrame writes one message of dataType to w.
+func writeFrame(w io.Writer, dataType string, data []byte) error {
+	frame, err := encodeFrame(dataType, data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(frame)
+	return err
+}
+
+// readFrame reads the next message from r. It returns io.EOF when the
+// stream is closed between two frames.
+func readFrame(r io.Reader) (string, []byte, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return "", nil, fmt.Errorf("truncated frame header")
+		}
+		return "", nil, err
+	}
+	if header[0] != wireVersion {
+		return "", nil, fmt.Errorf("unsupported wire version %d", header[0])
+	}
+	code := int(header[1])
+	if code >= len(messageTypes) || messageTypes[code] == "" {
+		return "", nil, fmt.Errorf("unknown message type %d", code)
+	}
+	size := binary.BigEndian.Uint32(header[2:])
+	if size > MaxMessageSize {
+		return "", nil, fmt.Errorf("message of %d bytes exceeds the limit of %d", size, MaxMessageSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", nil, fmt.Errorf("truncated %s message: %v", messageTypes[code], err)
+	}
+	return messageTypes[code], data, nil
+}
+
+// outboundStream is the stream reused for the messages to one peer.
+type outboundStream struct {
+	mu sync.Mutex
+	s  network.Stream
+}
+
+var (
+	outboundMu sync.Mutex
+	outbound   = make(map[peer.ID]*outboundStream)
+)
+
+func outboundStreamTo(id peer.ID) *outboundStream {
+	outboundMu.Lock()
+	defer outboundMu.Unlock()
+	o, ok := outbound[id]
+	if !ok {
+		o = &outboundStream{}
+		outbound[id] = o
+	}
+	return o
+}
+
+// dropStream closes the stream to a disconnected peer.
+func dropStream(id peer.ID) {
+	outboundMu.Lock()
+	o, ok := outbound[id]
+	delete(outbound, id)
+	outboundMu.Unlock()
+	if !ok {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.s != nil {
+		_ = o.s.Reset()
+		o.s = nil
+	}
+}
+
+// sendFrame writes a message on the stream to peerID, opening it first if
+// needed. A broken stream is replaced once.
+func sendFrame(ctx context.Context, node host.Host, peerID peer.ID, dataType string, data []byte) error {
+	o := outboundStreamTo(peerID)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if o.s == nil {
+			if o.s, err = node.NewStream(ctx, peerID, protocol.ID(wireProtocolID)); err != nil {
+				return fmt.Errorf("failed to open stream: %w", err)
+			}
+		}
+		_ = o.s.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err = writeFrame(o.s, dataType, data); err == nil {
+			return nil
+		}
+		_ = o.s.Reset()
+		o.s = nil
+	}
+	return fmt.Errorf("failed to write message to stream: %w", err)
+}
+
+// handleFramedStream reads the messages of a peer until it closes the
+// stream. Each message is processed on its own goroutine, as handlers may
+// wait for the local pod to catch up.
+func handleFramedStream(s network.Stream) {
+	r := bufio.NewReader(s)
+	from := s.Conn().RemotePeer()
+	for {
+		dataType, data, err := readFrame(r)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			scores.penalize(from, penaltyDecodeError, fmt.Errorf("invalid message: %v", err))
+			_ = s.Reset()
+			return
+		}
+		if !scores.allow(from, dataType) {
+			continue
+		}
+		go processSignedMessage(dataType, data, from)
+	}
+}
+
+// processSignedMessage authenticates a message received on a direct stream
+// before it is processed.
+func processSignedMessage(dataType string, payload []byte, from peer.ID) {
+	if authenticator == nil {
+		logs.Log.Warn(fmt.Sprintf("Dropped %s from %s, the junction account is not loaded", dataType, from))
+		return
+	}
+	sender, data, err := authenticator.open(dataType, payload)
+	if err == errReplayedMessage {
+		return
+	}
+	if err != nil {
+		logs.Log.Warn(fmt.Sprintf("Rejected %s from %s: %v", dataType, from, err))
+		scores.penalize(from, penaltyFor(err), err)
+		return
+	}
+	scores.reward(from)
+	ProcessGossipMessage(Node, dataType, data, from, sender)
+}
+
+// handleLegacyStream reads the single JSON types.GossipData written by
+// tracks on customProtocolID. Older tracks do not sign their messages, so
+// only signed ones are processed.
+func handleLegacyStream(s network.Stream) {
+	from := s.Conn().RemotePeer()
+	data, err := io.ReadAll(io.LimitReader(s, MaxMessageSize+1))
+	if err != nil {
+		logs.Log.Warn(fmt.Sprintf("Failed to read from %s: %v", from, err))
+		return
+	}
+	if len(data) > MaxMessageSize {
+		scores.penalize(from, penaltyDecodeError, fmt.Errorf("message exceeds the limit of %d bytes", MaxMessageSize))
+		_ = s.Reset()
+		return
+	}
+	dataType, dataByte, err := DecodeGossipData(data)
+	if err != nil {
+		scores.penalize(from, penaltyDecodeError, fmt.Errorf("invalid message: %v", err))
+		return
+	}
+	if !scores.allow(from, dataType) {
+		return
+	}
+	processSignedMessage(dataType, dataByte, from)
+}
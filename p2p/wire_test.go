@@ -0,0 +1,72 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestFramesRoundTrip(t *testing.T) {
+	// larger than the 8 KB the old handler read at once
+	proof := bytes.Repeat([]byte{0xab}, 100<<10)
+
+	var buf bytes.Buffer
+	if err := writeFrame(&buf, "podSubmitted", proof); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFrame(&buf, "podVerified", []byte(`{"PodNumber":2}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// a stream hands the bytes over in small reads
+	r := &oneByteReader{&buf}
+	for _, want := range []struct {
+		dataType string
+		data     []byte
+	}{
+		{"podSubmitted", proof},
+		{"podVerified", []byte(`{"PodNumber":2}`)},
+	} {
+		dataType, data, err := readFrame(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dataType != want.dataType || !bytes.Equal(data, want.data) {
+			t.Fatalf("read %s of %d bytes, want %s of %d bytes", dataType, len(data), want.dataType, len(want.data))
+		}
+	}
+	if _, _, err := readFrame(r); err != io.EOF {
+		t.Fatalf("read after the last frame returned %v, want io.EOF", err)
+	}
+}
+
+func TestReadFrameRejects(t *testing.T) {
+	header := func(version, code byte, size uint32) []byte {
+		h := []byte{version, code, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(h[2:], size)
+		return h
+	}
+	for name, frame := range map[string][]byte{
+		"version":   header(2, 1, 0),
+		"type":      header(wireVersion, 200, 0),
+		"size":      header(wireVersion, 1, MaxMessageSize+1),
+		"truncated": append(header(wireVersion, 1, 10), 1, 2, 3),
+	} {
+		if _, _, err := readFrame(bytes.NewReader(frame)); err == nil || err == io.EOF {
+			t.Errorf("%s: readFrame returned %v", name, err)
+		}
+	}
+	if err := writeFrame(io.Discard, "unknown", nil); err == nil {
+		t.Error("writeFrame accepted an unknown type")
+	}
+}
+
+type oneByteReader struct{ r io.Reader }
+
+func (o *oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return o.r.Read(p[:1])
+}
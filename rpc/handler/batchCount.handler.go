@@ -13,8 +13,8 @@ const BatchCountKey = "batchCount"
 
 func HandleGetBatchCount(c *gin.Context, Params []any) {
 	logger := logrus.New()
-	staticDB := shared.Node.NodeConnections.GetStaticDatabaseConnection()
-	currentPodNumber, err := staticDB.Get([]byte(BatchCountKey), nil)
+	staticDB := shared.Node.Store.Static()
+	currentPodNumber, err := staticDB.Get([]byte(BatchCountKey))
 	if err != nil {
 		logger.WithField("batchCountKey", BatchCountKey).Error("Failed to get current pod number from database: ", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error in getting current pod number from database"})
@@ -17,8 +17,8 @@ type Handler struct {
 }
 
 func (h *Handler) getStateData() ([]byte, error) {
-	stateConnection := shared.Node.NodeConnections.GetStateDatabaseConnection()
-	return stateConnection.Get([]byte("podState"), nil)
+	stateConnection := shared.Node.Store.PodState()
+	return stateConnection.Get([]byte("podState"))
 }
 
 func (h *Handler) unmarshalPodStateData(data []byte, out *types.PodState) error {
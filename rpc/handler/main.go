@@ -38,6 +38,18 @@ func RouterHandler(c *gin.Context) {
 		HandleGetBatchCount(c, requestBody.Params) // Assuming this is defined
 	case "tracks_getPodByNumber":
 		HandleGetPodByNumber(c, requestBody.Params) // Assuming this is defined
+	case "tracks_verifyDA":
+		HandleVerifyDA(c, requestBody.Params)
+	case "tracks_getReceipt":
+		HandleGetReceipt(c, requestBody.Params)
+	case "tracks_getTxnByHash":
+		HandleGetTxnByHash(c, requestBody.Params)
+	case "tracks_getTxnsByAddress":
+		HandleGetTxnsByAddress(c, requestBody.Params)
+	case "tracks_peerStatus":
+		HandleGetPeerStatus(c)
+	case "tracks_getVoteDisagreements":
+		HandleGetVoteDisagreements(c, requestBody.Params)
 	default:
 		errorMsg := "No method exists with the name " + requestBody.Method
 		respondWithError(c, Log, 4, errorMsg, 404)
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"github.com/airchains-network/decentralized-sequencer/p2p"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// HandleGetPeerStatus returns the score of each connected track and the
+// peers banned for misbehaving.
+func HandleGetPeerStatus(c *gin.Context) {
+	Log := logrus.New()
+	respondWithSuccess(c, Log, p2p.GetPeersStatus(), "success")
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
+	junctionTypes "github.com/airchains-network/decentralized-sequencer/junction/types"
 	"github.com/airchains-network/decentralized-sequencer/node/shared"
 	"github.com/airchains-network/decentralized-sequencer/types"
 	"github.com/gin-gonic/gin"
@@ -15,19 +16,19 @@ var Log *logrus.Logger
 
 func HandleGetPodByNumber(c *gin.Context, Params []interface{}) {
 	Log := logrus.New()
-	batchDB := shared.Node.NodeConnections.GetPodsDatabaseConnection()
-	daDB := shared.Node.NodeConnections.GetDataAvailabilityDatabaseConnection()
+	batchDB := shared.Node.Store.Pods()
+	daDB := shared.Node.Store.DA()
 	podKey := fmt.Sprintf("pod-%.0f", Params[0])
 	daKey := fmt.Sprintf("da-%.0f", Params[0])
 
 	fmt.Println(daKey)
-	podDataByte, err := batchDB.Get([]byte(podKey), nil)
+	podDataByte, err := batchDB.Get([]byte(podKey))
 	if err != nil {
 		Log.Error("Failed to get pod data: ", err)
 		respondWithError(c, Log, 3, "Failed to get pod data", 500)
 		return
 	}
-	daDataByte, err := daDB.Get([]byte(daKey), nil)
+	daDataByte, err := daDB.Get([]byte(daKey))
 	if err != nil {
 		Log.Error("Failed to get pod data: ", err)
 		respondWithError(c, Log, 3, "Failed to get da data", 500)
@@ -66,6 +67,7 @@ func HandleGetPodByNumber(c *gin.Context, Params []interface{}) {
 		VerifyPodTxHash     string
 		VRFValidationTxHash string
 		VRFInitiationTxHash string
+		VrfDisputeResult    *junctionTypes.VrfDisputeResult `json:",omitempty"`
 	}
 
 	responseData.LatestPodHeight = podData.LatestPodHeight
@@ -84,6 +86,7 @@ func HandleGetPodByNumber(c *gin.Context, Params []interface{}) {
 	responseData.VerifyPodTxHash = podData.VerifyPodTxHash
 	responseData.VRFValidationTxHash = podData.VRFValidationTxHash
 	responseData.VRFInitiationTxHash = podData.VRFInitiationTxHash
+	responseData.VrfDisputeResult = podData.VrfDisputeResult
 
 	respondWithSuccess(c, Log, responseData, "success")
 	return
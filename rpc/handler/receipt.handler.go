@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"github.com/airchains-network/decentralized-sequencer/blocksync"
+	"github.com/airchains-network/decentralized-sequencer/node/shared"
+	"github.com/airchains-network/decentralized-sequencer/store"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// HandleGetReceipt returns the receipt and logs stored for a transaction
+// number of an EVM station.
+func HandleGetReceipt(c *gin.Context, Params []interface{}) {
+	Log := logrus.New()
+	if len(Params) == 0 {
+		respondWithError(c, Log, 5, "Transaction number is required", 400)
+		return
+	}
+	txnNumber, ok := Params[0].(float64)
+	if !ok || txnNumber < 1 {
+		respondWithError(c, Log, 5, "Transaction number must be a positive number", 400)
+		return
+	}
+
+	txnDB := shared.Node.Store.Txns()
+	receipt, err := blocksync.GetEVMReceipt(txnDB, int(txnNumber))
+	if err == store.ErrNotFound {
+		respondWithError(c, Log, 3, "Receipt not found", 404)
+		return
+	}
+	if err != nil {
+		Log.Error("Failed to get receipt: ", err)
+		respondWithError(c, Log, 3, "Failed to get receipt", 500)
+		return
+	}
+
+	respondWithSuccess(c, Log, receipt, "success")
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/blocksync"
+	"github.com/airchains-network/decentralized-sequencer/config"
+	"github.com/airchains-network/decentralized-sequencer/node/shared"
+	"github.com/airchains-network/decentralized-sequencer/store"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultTxnsByAddressLimit = 100
+	maxTxnsByAddressLimit     = 1000
+)
+
+// TxnLocation tells where a transaction is stored and which pod batches it.
+// PodIncluded is set once that pod has been verified and saved.
+type TxnLocation struct {
+	TxnNumber   int
+	PodNumber   int
+	PodIncluded bool
+}
+
+func txnLocation(txnNumber int) TxnLocation {
+	podNumber := (txnNumber-1)/config.PODSize + 1
+	batchDB := shared.Node.Store.Pods()
+	included, _ := batchDB.Has([]byte(fmt.Sprintf("pod-%d", podNumber)))
+	return TxnLocation{TxnNumber: txnNumber, PodNumber: podNumber, PodIncluded: included}
+}
+
+// HandleGetTxnByHash returns the stored transaction with the given hash and
+// the pod it belongs to.
+func HandleGetTxnByHash(c *gin.Context, Params []interface{}) {
+	Log := logrus.New()
+	if len(Params) == 0 {
+		respondWithError(c, Log, 5, "Transaction hash is required", 400)
+		return
+	}
+	hash, ok := Params[0].(string)
+	if !ok || hash == "" {
+		respondWithError(c, Log, 5, "Transaction hash must be a string", 400)
+		return
+	}
+
+	txnDB := shared.Node.Store.Txns()
+	txnNumber, err := blocksync.GetTxnNumberByHash(txnDB, hash)
+	if err == store.ErrNotFound {
+		respondWithError(c, Log, 3, "Transaction not found", 404)
+		return
+	}
+	if err != nil {
+		Log.Error("Failed to look up transaction hash: ", err)
+		respondWithError(c, Log, 3, "Failed to look up transaction hash", 500)
+		return
+	}
+
+	txData, err := txnDB.Get([]byte(fmt.Sprintf("txns-%d", txnNumber)))
+	if err != nil {
+		Log.Error("Failed to get transaction: ", err)
+		respondWithError(c, Log, 3, "Failed to get transaction", 500)
+		return
+	}
+
+	var responseData struct {
+		TxnLocation
+		Transaction json.RawMessage
+	}
+	responseData.TxnLocation = txnLocation(txnNumber)
+	responseData.Transaction = txData
+
+	respondWithSuccess(c, Log, responseData, "success")
+}
+
+// HandleGetTxnsByAddress lists the transactions sent or received by an
+// address. Params are the address and optionally an offset and a limit.
+func HandleGetTxnsByAddress(c *gin.Context, Params []interface{}) {
+	Log := logrus.New()
+	if len(Params) == 0 {
+		respondWithError(c, Log, 5, "Address is required", 400)
+		return
+	}
+	address, ok := Params[0].(string)
+	if !ok || address == "" {
+		respondWithError(c, Log, 5, "Address must be a string", 400)
+		return
+	}
+
+	offset, limit := 0, defaultTxnsByAddressLimit
+	if len(Params) > 1 {
+		value, ok := Params[1].(float64)
+		if !ok || value < 0 {
+			respondWithError(c, Log, 5, "Offset must be a non negative number", 400)
+			return
+		}
+		offset = int(value)
+	}
+	if len(Params) > 2 {
+		value, ok := Params[2].(float64)
+		if !ok || value < 1 || value > maxTxnsByAddressLimit {
+			respondWithError(c, Log, 5, fmt.Sprintf("Limit must be between 1 and %d", maxTxnsByAddressLimit), 400)
+			return
+		}
+		limit = int(value)
+	}
+
+	txnDB := shared.Node.Store.Txns()
+	txnNumbers, err := blocksync.GetTxnNumbersByAddress(txnDB, address, offset, limit)
+	if err != nil {
+		Log.Error("Failed to look up address: ", err)
+		respondWithError(c, Log, 3, "Failed to look up address", 500)
+		return
+	}
+
+	locations := make([]TxnLocation, 0, len(txnNumbers))
+	for _, txnNumber := range txnNumbers {
+		locations = append(locations, txnLocation(txnNumber))
+	}
+
+	respondWithSuccess(c, Log, locations, "success")
+}
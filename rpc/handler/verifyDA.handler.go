@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/da"
+	"github.com/airchains-network/decentralized-sequencer/node/shared"
+	"github.com/airchains-network/decentralized-sequencer/types"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// HandleVerifyDA reads back the DA blob of the requested pod and checks it
+// against the commitment recorded when the pod was posted. Blobs in the pod
+// blob format are decoded and returned alongside the result.
+func HandleVerifyDA(c *gin.Context, Params []interface{}) {
+	Log := logrus.New()
+	if len(Params) == 0 {
+		respondWithError(c, Log, 5, "Pod number is required", 400)
+		return
+	}
+
+	daDB := shared.Node.Store.DA()
+	daKey := fmt.Sprintf("da-%.0f", Params[0])
+	daDataByte, err := daDB.Get([]byte(daKey))
+	if err != nil {
+		Log.Error("Failed to get da data: ", err)
+		respondWithError(c, Log, 3, "Failed to get da data", 500)
+		return
+	}
+
+	var daPointer types.DAStruct
+	if err = json.Unmarshal(daDataByte, &daPointer); err != nil {
+		Log.Error("Failed to unmarshal da data: ", err)
+		respondWithError(c, Log, 4, "Failed to unmarshal da data", 500)
+		return
+	}
+
+	client, err := da.NewClient(shared.Node.Config.DA)
+	if err != nil {
+		Log.Error("Failed to create DA client: ", err)
+		respondWithError(c, Log, 6, err.Error(), 500)
+		return
+	}
+
+	var responseData struct {
+		DAKey        string
+		DAClientName string
+		Commitment   string
+		Verified     bool
+		Error        string      `json:",omitempty"`
+		Pod          *da.PodBlob `json:",omitempty"`
+	}
+	responseData.DAKey = daPointer.DAKey
+	responseData.DAClientName = daPointer.DAClientName
+	responseData.Commitment = daPointer.Commitment
+
+	daData, err := da.Verify(client, daPointer)
+	if err != nil {
+		responseData.Error = err.Error()
+	} else {
+		responseData.Verified = true
+		// pods posted before the blob format only carry transaction hashes
+		if pod, err := da.DecodePodBlob(daData); err == nil {
+			responseData.Pod = pod
+		}
+	}
+
+	respondWithSuccess(c, Log, responseData, "success")
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/node/shared"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// HandleGetVoteDisagreements returns the pod votes of tracks that derived
+// another TracksAppHash than this one, for the pod given as first param or
+// for every pod.
+func HandleGetVoteDisagreements(c *gin.Context, Params []interface{}) {
+	Log := logrus.New()
+	prefix := "disagreement-"
+	if len(Params) > 0 {
+		podNumber, ok := Params[0].(float64)
+		if !ok {
+			respondWithError(c, Log, 3, "pod number should be a number", 400)
+			return
+		}
+		prefix = fmt.Sprintf("disagreement-%.0f-", podNumber)
+	}
+
+	disagreements := []json.RawMessage{}
+	err := shared.Node.Store.Disagreements().Iterate([]byte(prefix), func(_ []byte, value []byte) bool {
+		disagreements = append(disagreements, append(json.RawMessage(nil), value...))
+		return true
+	})
+	if err != nil {
+		Log.Error("Failed to read vote disagreements: ", err)
+		respondWithError(c, Log, 3, "Failed to read vote disagreements", 500)
+		return
+	}
+	respondWithSuccess(c, Log, disagreements, "success")
+}
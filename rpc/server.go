@@ -2,24 +2,38 @@ package rpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/airchains-network/decentralized-sequencer/config"
 	"github.com/airchains-network/decentralized-sequencer/rpc/handler"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/cors"
 	"github.com/rs/zerolog/log"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/netutil"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
+	"net/url"
+	"path/filepath"
 	"sync"
-	"syscall"
+	"time"
 )
 
 type Server struct {
 	httpServer *http.Server
 	router     *gin.Engine
 	Log        *logrus.Logger
+
+	listenAddress      string
+	maxOpenConnections int
+	tlsCertFile        string
+	tlsKeyFile         string
 }
 
-func NewServer() *Server {
+// NewServer builds the RPC server from the [rpc] section of the config.
+// CORS is only enabled when cors_allowed_origins is not empty, and the server
+// uses HTTPS when both tls_cert_file and tls_key_file are set.
+func NewServer(cfg *config.RPCConfig) (*Server, error) {
 	// Create a new logger instance
 	logger := logrus.New()
 	// Set logrus to only log the warning severity or above.
@@ -27,29 +41,69 @@ func NewServer() *Server {
 	// Use the JSON formatter
 	logger.SetFormatter(&logrus.JSONFormatter{})
 
+	listenAddress, err := hostPort(cfg.ListenAddress)
+	if err != nil {
+		return nil, err
+	}
 	server := &Server{
-		Log: logger,
+		Log:                logger,
+		listenAddress:      listenAddress,
+		maxOpenConnections: cfg.MaxOpenConnections,
+	}
+	if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" {
+		server.tlsCertFile = configFile(cfg.TLSCertFile)
+		server.tlsKeyFile = configFile(cfg.TLSKeyFile)
 	}
 
 	// Set gin to release mode
 	gin.SetMode(gin.ReleaseMode)
 	server.router = gin.Default()
+	if cfg.MaxBodyBytes > 0 {
+		server.router.Use(limitBody(cfg.MaxBodyBytes))
+	}
 
 	server.router.POST("/", func(c *gin.Context) {
 		handler.RouterHandler(c)
 	})
 
+	var h http.Handler = server.router
+	if len(cfg.CORSAllowedOrigins) > 0 {
+		h = cors.New(cors.Options{
+			AllowedOrigins: cfg.CORSAllowedOrigins,
+			AllowedMethods: cfg.CORSAllowedMethods,
+			AllowedHeaders: cfg.CORSAllowedHeaders,
+		}).Handler(h)
+	}
+
 	server.httpServer = &http.Server{
-		Addr:    ":2024",
-		Handler: server.router,
+		Addr:              listenAddress,
+		Handler:           h,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ReadHeaderTimeout: 10 * time.Second,
 	}
 
-	return server
+	return server, nil
 }
 
+// Start binds the listen address and serves in the background, so an
+// address already in use is returned here.
 func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.listenAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", s.listenAddress, err)
+	}
+	if s.maxOpenConnections > 0 {
+		listener = netutil.LimitListener(listener, s.maxOpenConnections)
+	}
+
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		var err error
+		if s.tlsCertFile != "" {
+			err = s.httpServer.ServeTLS(listener, s.tlsCertFile, s.tlsKeyFile)
+		} else {
+			err = s.httpServer.Serve(listener)
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
 			s.Log.WithField("error", err).Error("Error starting server")
 		}
 	}()
@@ -63,20 +117,66 @@ func (s *Server) Stop(ctx context.Context) {
 	}
 }
 
-func StartRPC(wg *sync.WaitGroup) {
+// StartRPC serves the RPC until ctx is done.
+func StartRPC(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	server := NewServer()
+	conf, err := config.Current()
+	if err != nil {
+		log.Error().Str("module", "rpc").Err(err).Msg("Failed to load config")
+		return
+	}
+	server, err := NewServer(conf.RPC)
+	if err != nil {
+		log.Error().Str("module", "rpc").Err(err).Msg("Invalid RPC config")
+		return
+	}
 
 	if err := server.Start(); err != nil {
 		server.Log.WithField("error", err).Fatal("Failed to start server")
 	}
-	server.Log.Info("Server Started Successfully on Port 2024")
-	log.Info().Str("module", "rpc").Msg("RPC Server Stared at Port 2024 Successfully")
-
-	stopChan := make(chan os.Signal, 1)
-	signal.Notify(stopChan, syscall.SIGTERM)
+	scheme := "http"
+	if server.tlsCertFile != "" {
+		scheme = "https"
+	}
+	log.Info().Str("module", "rpc").Msgf("RPC Server Started at %s://%s", scheme, server.listenAddress)
 
-	<-stopChan
+	<-ctx.Done()
 	server.Log.Info("Received shutdown signal")
+	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server.Stop(stopCtx)
+}
+
+// hostPort turns a tcp://host:port listen address into host:port.
+func hostPort(listenAddress string) (string, error) {
+	u, err := url.Parse(listenAddress)
+	if err != nil || u.Scheme != "tcp" || u.Host == "" {
+		return "", fmt.Errorf("rpc listen address %q must look like tcp://host:port", listenAddress)
+	}
+	return u.Host, nil
+}
+
+// configFile resolves a path relative to the config directory.
+func configFile(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return config.ConfigPath(path)
+}
+
+// limitBody rejects requests larger than maxBytes.
+func limitBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, handler.ResponseBody{
+				JsonRPC: "2.0",
+				ID:      "1",
+				Error:   handler.NewErrorResponse(-32600, "request body too large"),
+			})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
 }
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"bytes"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltBucket is the bucket holding every key of a bbolt store. Namespaces are
+// key prefixes, as with goleveldb, so one bucket is enough.
+var boltBucket = []byte("tracks")
+
+// boltDB is a KV backed by bbolt.
+type boltDB struct {
+	db *bolt.DB
+}
+
+// OpenBoltDB opens, or creates, the bbolt database file at path.
+func OpenBoltDB(path string) (KV, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltDB{db: db}, nil
+}
+
+// seek returns the value of key and whether it exists. bbolt's Get can not
+// tell a missing key from an empty value, which the index entries use.
+func seek(b *bolt.Bucket, key []byte) ([]byte, bool) {
+	k, v := b.Cursor().Seek(key)
+	if k == nil || !bytes.Equal(k, key) {
+		return nil, false
+	}
+	return v, true
+}
+
+func (b *boltDB) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		v, found := seek(tx.Bucket(boltBucket), key)
+		if !found {
+			return ErrNotFound
+		}
+		// v is only valid for the life of the transaction
+		value = append([]byte{}, v...)
+		return nil
+	})
+	return value, err
+}
+
+func (b *boltDB) Has(key []byte) (bool, error) {
+	var found bool
+	err := b.db.View(func(tx *bolt.Tx) error {
+		_, found = seek(tx.Bucket(boltBucket), key)
+		return nil
+	})
+	return found, err
+}
+
+func (b *boltDB) Put(key []byte, value []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put(key, value)
+	})
+}
+
+func (b *boltDB) Delete(key []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete(key)
+	})
+}
+
+func (b *boltDB) Write(batch *Batch) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		for _, op := range batch.Ops() {
+			var err error
+			if op.Delete {
+				err = bucket.Delete(op.Key)
+			} else {
+				err = bucket.Put(op.Key, op.Value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *boltDB) Iterate(prefix []byte, fn func(key []byte, value []byte) bool) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if !fn(k, v) {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+func (b *boltDB) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// levelDB is a KV backed by goleveldb.
+type levelDB struct {
+	db *leveldb.DB
+}
+
+// OpenLevelDB opens, or creates, the goleveldb database at path.
+func OpenLevelDB(path string) (KV, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &levelDB{db: db}, nil
+}
+
+func (l *levelDB) Get(key []byte) ([]byte, error) {
+	value, err := l.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+func (l *levelDB) Has(key []byte) (bool, error) {
+	return l.db.Has(key, nil)
+}
+
+func (l *levelDB) Put(key []byte, value []byte) error {
+	return l.db.Put(key, value, nil)
+}
+
+func (l *levelDB) Delete(key []byte) error {
+	return l.db.Delete(key, nil)
+}
+
+func (l *levelDB) Write(b *Batch) error {
+	batch := new(leveldb.Batch)
+	for _, op := range b.Ops() {
+		if op.Delete {
+			batch.Delete(op.Key)
+		} else {
+			batch.Put(op.Key, op.Value)
+		}
+	}
+	return l.db.Write(batch, nil)
+}
+
+func (l *levelDB) Iterate(prefix []byte, fn func(key []byte, value []byte) bool) error {
+	iter := l.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+	for iter.Next() {
+		if !fn(iter.Key(), iter.Value()) {
+			break
+		}
+	}
+	return iter.Error()
+}
+
+func (l *levelDB) Close() error {
+	return l.db.Close()
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	logs "github.com/airchains-network/decentralized-sequencer/log"
+)
+
+// Backends accepted by Open.
+const (
+	GoLevelDBBackend = "goleveldb"
+	BoltDBBackend    = "bbolt"
+)
+
+const (
+	// levelDBDirName is the directory inside the data directory that holds
+	// the goleveldb store and, before it, one LevelDB per namespace.
+	levelDBDirName = "leveldb"
+	// storeDirName is the goleveldb store inside levelDBDirName.
+	storeDirName = "store"
+	// boltDirName and boltFileName locate the bbolt store inside the data
+	// directory.
+	boltDirName  = "bbolt"
+	boltFileName = "store.db"
+
+	migrateBatchSize = 1000
+)
+
+// Open opens the store of the given backend in dataDir. The LevelDBs kept per
+// namespace in dataDir/leveldb by earlier versions are copied into the store
+// the first time it is opened and renamed to <name>.migrated. Switching the
+// backend of an existing store does not carry its data over.
+func Open(backend string, dataDir string) (Store, error) {
+	kv, err := openKV(backend, dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s store: %v", backend, err)
+	}
+	s := New(kv)
+	if err = migrateLegacy(s, filepath.Join(dataDir, levelDBDirName)); err != nil {
+		kv.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func openKV(backend string, dataDir string) (KV, error) {
+	switch backend {
+	case GoLevelDBBackend, "":
+		return OpenLevelDB(filepath.Join(dataDir, levelDBDirName, storeDirName))
+	case BoltDBBackend:
+		dir := filepath.Join(dataDir, boltDirName)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+		return OpenBoltDB(filepath.Join(dir, boltFileName))
+	default:
+		return nil, fmt.Errorf("unknown db backend %q, expected %s or %s", backend, GoLevelDBBackend, BoltDBBackend)
+	}
+}
+
+// migrateLegacy copies every key of legacyDir/<namespace> into its namespace.
+func migrateLegacy(s Store, legacyDir string) error {
+	for _, name := range Namespaces {
+		legacyPath := filepath.Join(legacyDir, name)
+		if info, err := os.Stat(legacyPath); err != nil || !info.IsDir() {
+			continue
+		}
+
+		logs.Log.Info(fmt.Sprintf("Migrating %s database into the store", name))
+		legacy, err := OpenLevelDB(legacyPath)
+		if err != nil {
+			return fmt.Errorf("failed to open legacy %s database: %v", name, err)
+		}
+		err = copyInto(s, namespaceByName(s, name), legacy)
+		legacy.Close()
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s database: %v", name, err)
+		}
+		if err = os.Rename(legacyPath, legacyPath+".migrated"); err != nil {
+			return fmt.Errorf("failed to retire legacy %s database: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func copyInto(s Store, ns Namespace, legacy KV) error {
+	batch := new(Batch)
+	var writeErr error
+	err := legacy.Iterate(nil, func(key []byte, value []byte) bool {
+		batch.Put(ns, append([]byte(nil), key...), append([]byte(nil), value...))
+		if batch.Len() >= migrateBatchSize {
+			if writeErr = s.Write(batch); writeErr != nil {
+				return false
+			}
+			batch = new(Batch)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	return s.Write(batch)
+}
+
+func namespaceByName(s Store, name string) Namespace {
+	switch name {
+	case BlocksNamespace:
+		return s.Blocks()
+	case TxnsNamespace:
+		return s.Txns()
+	case PodsNamespace:
+		return s.Pods()
+	case PodStateNamespace:
+		return s.PodState()
+	case DANamespace:
+		return s.DA()
+	case StaticNamespace:
+		return s.Static()
+	case ProofsNamespace:
+		return s.Proofs()
+	case PublicWitnessNamespace:
+		return s.PublicWitness()
+	case DisagreementsNamespace:
+		return s.Disagreements()
+	default:
+		return s.Mock()
+	}
+}
@@ -0,0 +1,201 @@
+// Package store keeps all sequencer data in a single key value database.
+// Each kind of record lives in its own namespace, a key prefix, so records of
+// different kinds can still be updated together in one atomic Batch.
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when the key does not exist.
+var ErrNotFound = errors.New("store: key not found")
+
+// KV is the key value database underneath a Store.
+type KV interface {
+	Get(key []byte) ([]byte, error)
+	Has(key []byte) (bool, error)
+	Put(key []byte, value []byte) error
+	Delete(key []byte) error
+	// Write applies every operation of b atomically.
+	Write(b *Batch) error
+	// Iterate calls fn for every key starting with prefix, in key order, until
+	// fn returns false.
+	Iterate(prefix []byte, fn func(key []byte, value []byte) bool) error
+	Close() error
+}
+
+// Store gives access to the namespaces of the sequencer database.
+type Store interface {
+	// Blocks holds block_N / BlockN and blockCount.
+	Blocks() Namespace
+	// Txns holds txns-N, receipt-N, their indexes and txnCount.
+	Txns() Namespace
+	// Pods holds the verified pod-N records.
+	Pods() Namespace
+	// PodState holds the state of the pod being processed.
+	PodState() Namespace
+	// DA holds the DA pointers da-N.
+	DA() Namespace
+	// Static holds batchCount and batchStartIndex.
+	Static() Namespace
+	Proofs() Namespace
+	PublicWitness() Namespace
+	// Mock holds the blobs of the mock DA layer.
+	Mock() Namespace
+	// Disagreements holds the pod votes of tracks that derived another
+	// TracksAppHash, disagreement-<pod>-<address>.
+	Disagreements() Namespace
+
+	// Write applies b, which may span namespaces, atomically.
+	Write(b *Batch) error
+	Close() error
+}
+
+// Namespace names, also the directories of the LevelDBs they replace.
+const (
+	BlocksNamespace        = "blocks"
+	TxnsNamespace          = "tx"
+	PodsNamespace          = "batches"
+	PodStateNamespace      = "state"
+	DANamespace            = "da"
+	StaticNamespace        = "static"
+	ProofsNamespace        = "proof"
+	PublicWitnessNamespace = "publicWitness"
+	MockNamespace          = "mock"
+	DisagreementsNamespace = "disagreements"
+)
+
+// Namespaces lists every namespace of a Store.
+var Namespaces = []string{
+	BlocksNamespace,
+	TxnsNamespace,
+	PodsNamespace,
+	PodStateNamespace,
+	DANamespace,
+	StaticNamespace,
+	ProofsNamespace,
+	PublicWitnessNamespace,
+	MockNamespace,
+	DisagreementsNamespace,
+}
+
+type store struct {
+	kv KV
+}
+
+// New returns a Store keeping its namespaces in kv.
+func New(kv KV) Store {
+	return &store{kv: kv}
+}
+
+func (s *store) namespace(name string) Namespace {
+	return Namespace{kv: s.kv, prefix: []byte(name + "/")}
+}
+
+func (s *store) Blocks() Namespace        { return s.namespace(BlocksNamespace) }
+func (s *store) Txns() Namespace          { return s.namespace(TxnsNamespace) }
+func (s *store) Pods() Namespace          { return s.namespace(PodsNamespace) }
+func (s *store) PodState() Namespace      { return s.namespace(PodStateNamespace) }
+func (s *store) DA() Namespace            { return s.namespace(DANamespace) }
+func (s *store) Static() Namespace        { return s.namespace(StaticNamespace) }
+func (s *store) Proofs() Namespace        { return s.namespace(ProofsNamespace) }
+func (s *store) PublicWitness() Namespace { return s.namespace(PublicWitnessNamespace) }
+func (s *store) Mock() Namespace          { return s.namespace(MockNamespace) }
+func (s *store) Disagreements() Namespace { return s.namespace(DisagreementsNamespace) }
+
+func (s *store) Write(b *Batch) error { return s.kv.Write(b) }
+func (s *store) Close() error         { return s.kv.Close() }
+
+// Namespace is a view of the keys under one prefix. Keys passed to and
+// returned from its methods do not include the prefix.
+type Namespace struct {
+	kv     KV
+	prefix []byte
+}
+
+func (n Namespace) key(key []byte) []byte {
+	full := make([]byte, 0, len(n.prefix)+len(key))
+	return append(append(full, n.prefix...), key...)
+}
+
+func (n Namespace) Get(key []byte) ([]byte, error) {
+	return n.kv.Get(n.key(key))
+}
+
+func (n Namespace) Has(key []byte) (bool, error) {
+	return n.kv.Has(n.key(key))
+}
+
+func (n Namespace) Put(key []byte, value []byte) error {
+	return n.kv.Put(n.key(key), value)
+}
+
+func (n Namespace) Delete(key []byte) error {
+	return n.kv.Delete(n.key(key))
+}
+
+// Write applies b to the store the namespace belongs to. b may hold
+// operations on other namespaces of the same store.
+func (n Namespace) Write(b *Batch) error {
+	return n.kv.Write(b)
+}
+
+// Iterate calls fn for every key of the namespace starting with prefix.
+func (n Namespace) Iterate(prefix []byte, fn func(key []byte, value []byte) bool) error {
+	return n.kv.Iterate(n.key(prefix), func(key []byte, value []byte) bool {
+		return fn(key[len(n.prefix):], value)
+	})
+}
+
+// Counter reads a decimal counter such as txnCount. A missing counter is 0.
+func (n Namespace) Counter(key string) (int, error) {
+	data, err := n.Get([]byte(key))
+	if err == ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading %s: %v", key, err)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", key, data, err)
+	}
+	return count, nil
+}
+
+// Batch collects writes to one or more namespaces of a store so they can be
+// applied atomically.
+type Batch struct {
+	ops []BatchOp
+}
+
+// BatchOp is a single write of a Batch. Key includes the namespace prefix.
+type BatchOp struct {
+	Delete bool
+	Key    []byte
+	Value  []byte
+}
+
+func (b *Batch) Put(ns Namespace, key []byte, value []byte) {
+	b.ops = append(b.ops, BatchOp{Key: ns.key(key), Value: value})
+}
+
+func (b *Batch) Delete(ns Namespace, key []byte) {
+	b.ops = append(b.ops, BatchOp{Delete: true, Key: ns.key(key)})
+}
+
+// Ops returns the writes of b in the order they were added.
+func (b *Batch) Ops() []BatchOp {
+	return b.ops
+}
+
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+func (b *Batch) Reset() {
+	b.ops = b.ops[:0]
+}
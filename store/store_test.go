@@ -0,0 +1,80 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNamespacesAreIsolated(t *testing.T) {
+	for _, backend := range []string{GoLevelDBBackend, BoltDBBackend} {
+		t.Run(backend, func(t *testing.T) {
+			s, err := Open(backend, t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+			testNamespaces(t, s)
+		})
+	}
+}
+
+func testNamespaces(t *testing.T, s Store) {
+	batch := new(Batch)
+	batch.Put(s.Txns(), []byte("txnCount"), []byte("7"))
+	batch.Put(s.Blocks(), []byte("blockCount"), []byte("3"))
+	batch.Put(s.Txns(), []byte("txhash-0xab"), []byte("7"))
+	batch.Put(s.Txns(), []byte("txaddr-0xcd-1"), nil)
+	if err := s.Write(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := s.Txns().Counter("txnCount"); err != nil || n != 7 {
+		t.Fatalf("txnCount = %d, %v; want 7", n, err)
+	}
+	if _, err := s.Blocks().Get([]byte("txnCount")); err != ErrNotFound {
+		t.Fatalf("txnCount visible outside the tx namespace: %v", err)
+	}
+	if n, err := s.Static().Counter("batchCount"); err != nil || n != 0 {
+		t.Fatalf("missing counter = %d, %v; want 0", n, err)
+	}
+
+	if found, err := s.Txns().Has([]byte("txaddr-0xcd-1")); err != nil || !found {
+		t.Fatalf("empty value not found: %v, %v", found, err)
+	}
+
+	var keys []string
+	err := s.Txns().Iterate([]byte("txhash-"), func(key []byte, _ []byte) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	if err != nil || len(keys) != 1 || keys[0] != "txhash-0xab" {
+		t.Fatalf("Iterate returned %v, %v", keys, err)
+	}
+}
+
+func TestOpenMigratesLegacyDatabases(t *testing.T) {
+	dataDir := t.TempDir()
+	legacyDir := filepath.Join(dataDir, levelDBDirName, TxnsNamespace)
+	legacy, err := OpenLevelDB(legacyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = legacy.Put([]byte("txnCount"), []byte("42")); err != nil {
+		t.Fatal(err)
+	}
+	legacy.Close()
+
+	s, err := Open(GoLevelDBBackend, dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if n, err := s.Txns().Counter("txnCount"); err != nil || n != 42 {
+		t.Fatalf("migrated txnCount = %d, %v; want 42", n, err)
+	}
+	if _, err = os.Stat(legacyDir + ".migrated"); err != nil {
+		t.Fatalf("legacy database was not retired: %v", err)
+	}
+}
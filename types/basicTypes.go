@@ -46,3 +46,23 @@ type TransactionStruct struct {
 	V                string `json:"v"`
 	Value            string `json:"value"`
 }
+
+// ReceiptStruct is the receipt of an EVM transaction, stored as receipt-N next
+// to txns-N.
+type ReceiptStruct struct {
+	TransactionHash   string      `json:"transactionHash"`
+	BlockNumber       uint64      `json:"blockNumber"`
+	Status            uint64      `json:"status"`
+	GasUsed           string      `json:"gasUsed"`
+	CumulativeGasUsed string      `json:"cumulativeGasUsed"`
+	EffectiveGasPrice string      `json:"effectiveGasPrice"`
+	ContractAddress   string      `json:"contractAddress,omitempty"`
+	Logs              []LogStruct `json:"logs"`
+}
+
+type LogStruct struct {
+	Address  string   `json:"address"`
+	Topics   []string `json:"topics"`
+	Data     string   `json:"data"`
+	LogIndex uint     `json:"logIndex"`
+}
@@ -1,5 +1,7 @@
 package types
 
+import "encoding/json"
+
 type DAConfigType struct {
 	DALayer    string
 	DARpc      string
@@ -8,27 +10,37 @@ type DAConfigType struct {
 }
 
 type AvailSuccessResponse struct {
-	BlockNumber int
-	BlockHash   string
-	Hash        string
-	Index       int
+	BlockNumber int    `json:"block_number"`
+	BlockHash   string `json:"block_hash"`
+	Hash        string `json:"hash"`
+	Index       int    `json:"index"`
 }
 
-type CelestiaSuccessResponse struct {
-	Jsonrpc string
-	Result  int
-	ID      int
+type AvailBlockData struct {
+	BlockNumber      int `json:"block_number"`
+	DataTransactions []struct {
+		Data      string `json:"data"`
+		Extrinsic string `json:"extrinsic"`
+	} `json:"data_transactions"`
 }
 
-type CelestiaErrorResponse struct {
-	Jsonrpc string
-	ID      int
-	Error   struct {
-		Code    int
-		Message string
+type CelestiaRPCResponse struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
 	} `json:"error"`
 }
 
+type CelestiaBlob struct {
+	Namespace    string `json:"namespace"`
+	Data         string `json:"data"`
+	ShareVersion int    `json:"share_version"`
+	Commitment   string `json:"commitment"`
+}
+
 type MockDAStruck struct {
 	DataBlob    []byte
 	BatchNumber int
@@ -41,10 +53,6 @@ type DAStruct struct {
 	BatchNumber       string
 	PreviousStateHash string
 	CurrentStateHash  string
-}
-
-type FinalizeDA struct {
-	CompressedHash []string
-	Proof          []byte
-	PodNumber      int
+	Commitment        string `json:",omitempty"` // hex sha256 of the posted blob
+	DataLength        int    `json:",omitempty"`
 }
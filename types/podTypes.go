@@ -13,8 +13,10 @@ type BatchStruct struct {
 }
 
 type Votes struct {
-	PeerID string // TODO change this type to proper Peer ID Type
-	Vote   bool
+	PeerID        string // TODO change this type to proper Peer ID Type
+	Vote          bool
+	Address       string
+	TracksAppHash []byte
 }
 type PodState struct {
 	LatestPodHeight     uint64
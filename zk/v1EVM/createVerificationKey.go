@@ -2,6 +2,7 @@ package v1EVM
 
 import (
 	"encoding/json"
+	"github.com/airchains-network/decentralized-sequencer/config"
 	logs "github.com/airchains-network/decentralized-sequencer/log"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
@@ -10,9 +11,8 @@ import (
 
 // CreateVkPkNew generates and saves a new Proving Key and Verification Key if either file doesn't exist
 func CreateVkPkNew() {
-	homeDir, _ := os.UserHomeDir()
-	provingKeyFile := homeDir + "/.tracks/config/provingKey.txt"
-	verificationKeyFile := homeDir + "/.tracks/config/verificationKey.json"
+	provingKeyFile := config.ConfigPath("provingKey.txt")
+	verificationKeyFile := config.ConfigPath("verificationKey.json")
 
 	_, err1 := os.Stat(provingKeyFile)
 	_, err2 := os.Stat(verificationKeyFile)
@@ -50,9 +50,8 @@ func CreateVkPkNew() {
 }
 
 func GetVkPk() (groth16.ProvingKey, groth16.VerifyingKey, error) {
-	homeDir, _ := os.UserHomeDir()
-	provingKeyFile := homeDir + "/.tracks/config/provingKey.txt"
-	verificationKeyFile := homeDir + "/.tracks/config/verificationKey.json"
+	provingKeyFile := config.ConfigPath("provingKey.txt")
+	verificationKeyFile := config.ConfigPath("verificationKey.json")
 
 	// Read Proving Key
 	pk, err := ReadProvingKeyFromFile2(provingKeyFile)
@@ -130,8 +130,7 @@ func GenerateProof(inputData types.BatchStruct, batchNum int) (any, string, []by
 	currentStatusHash := GetMerkleRootSecond(transactions)
 
 	//pk, err := ReadProvingKeyFromFile("provingKey.txt")
-	homeDir, _ := os.UserHomeDir()
-	provingKeyFile := homeDir + "/.tracks/config/provingKey.txt"
+	provingKeyFile := config.ConfigPath("provingKey.txt")
 	pk, err := ReadProvingKeyFromFile(provingKeyFile)
 
 	if err != nil {
@@ -208,14 +207,14 @@ func GenerateProof(inputData types.BatchStruct, batchNum int) (any, string, []by
 
 	publicWitness, _ := witness.Public()
 
-	publicWitnessDb := blocksync.GetPublicWitnessDbInstance()
+	publicWitnessDb := blocksync.GetStore().PublicWitness()
 	publicWitnessDbKey := fmt.Sprintf("public_witness_%d", batchNum)
 	publicWitnessDbValue, err := json.Marshal(publicWitness)
 	if err != nil {
 		fmt.Println("Error marshalling public witness:", err)
 		return nil, "", nil, err
 	}
-	err = publicWitnessDb.Put([]byte(publicWitnessDbKey), publicWitnessDbValue, nil)
+	err = publicWitnessDb.Put([]byte(publicWitnessDbKey), publicWitnessDbValue)
 	if err != nil {
 		fmt.Println("Error saving public witness:", err)
 		return nil, "", nil, err
@@ -226,14 +225,14 @@ func GenerateProof(inputData types.BatchStruct, batchNum int) (any, string, []by
 		return nil, "", nil, err
 	}
 
-	proofDb := blocksync.GetProofDbInstance()
+	proofDb := blocksync.GetStore().Proofs()
 	proofDbKey := fmt.Sprintf("proof_%d", batchNum)
 	proofDbValue, err := json.Marshal(proof)
 	if err != nil {
 		fmt.Println("Error marshalling proof:", err)
 		return nil, "", nil, err
 	}
-	err = proofDb.Put([]byte(proofDbKey), proofDbValue, nil)
+	err = proofDb.Put([]byte(proofDbKey), proofDbValue)
 	if err != nil {
 		fmt.Println("Error saving proof:", err)
 		return nil, "", nil, err
@@ -2,6 +2,7 @@ package prover
 
 import (
 	"encoding/json"
+	"github.com/airchains-network/decentralized-sequencer/config"
 	logs "github.com/airchains-network/decentralized-sequencer/log"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
@@ -9,9 +10,8 @@ import (
 )
 
 func CreateVkPkWasm() {
-	homeDir, _ := os.UserHomeDir()
-	provingKeyFile := homeDir + "/.tracks/config/provingKey.txt"
-	verificationKeyFile := homeDir + "/.tracks/config/verificationKey.json"
+	provingKeyFile := config.ConfigPath("provingKey.txt")
+	verificationKeyFile := config.ConfigPath("verificationKey.json")
 
 	_, err1 := os.Stat(provingKeyFile)
 	_, err2 := os.Stat(verificationKeyFile)
@@ -49,9 +49,8 @@ func CreateVkPkWasm() {
 }
 
 func GetVkPk() (groth16.ProvingKey, groth16.VerifyingKey, error) {
-	homeDir, _ := os.UserHomeDir()
-	provingKeyFile := homeDir + "/.tracks/config/provingKey.txt"
-	verificationKeyFile := homeDir + "/.tracks/config/verificationKey.json"
+	provingKeyFile := config.ConfigPath("provingKey.txt")
+	verificationKeyFile := config.ConfigPath("verificationKey.json")
 
 	// Read Proving Key
 	pk, err := ReadProvingKeyFromFile2(provingKeyFile)
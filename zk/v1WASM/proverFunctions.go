@@ -184,8 +184,7 @@ func GenerateProof(inputData types.BatchStruct, batchNum int) (any, string, []by
 		transactions = append(transactions, transaction)
 	}
 	currentStatusHash := GetMerkleRootCheck(transactions)
-	homeDir, _ := os.UserHomeDir()
-	provingKeyFile := homeDir + "/.tracks/config/provingKey.txt"
+	provingKeyFile := config.ConfigPath("provingKey.txt")
 	pk, err := ReadProvingKeyFromFile(provingKeyFile)
 	if err != nil {
 		fmt.Println("Error reading proving key:", err)
@@ -293,7 +292,7 @@ func GenerateProof(inputData types.BatchStruct, batchNum int) (any, string, []by
 	}
 	witnessVector := witness.Vector()
 	publicWitness, _ := witness.Public()
-	publicWitnessDb := blocksync.GetPublicWitnessDbInstance()
+	publicWitnessDb := blocksync.GetStore().PublicWitness()
 	publicWitnessDbKey := fmt.Sprintf("public_witness_%d", batchNum)
 	publicWitnessDbValue, err := json.Marshal(publicWitness)
 	if err != nil {
@@ -301,7 +300,7 @@ func GenerateProof(inputData types.BatchStruct, batchNum int) (any, string, []by
 		return nil, "", nil, err
 
 	}
-	err = publicWitnessDb.Put([]byte(publicWitnessDbKey), publicWitnessDbValue, nil)
+	err = publicWitnessDb.Put([]byte(publicWitnessDbKey), publicWitnessDbValue)
 	if err != nil {
 		fmt.Println("Error saving public witness:", err)
 		return nil, "", nil, err
@@ -314,7 +313,7 @@ func GenerateProof(inputData types.BatchStruct, batchNum int) (any, string, []by
 
 	}
 
-	proofDb := blocksync.GetProofDbInstance()
+	proofDb := blocksync.GetStore().Proofs()
 	proofDbKey := fmt.Sprintf("proof_%d", batchNum)
 	proofDbValue, err := json.Marshal(proof)
 	if err != nil {
@@ -322,7 +321,7 @@ func GenerateProof(inputData types.BatchStruct, batchNum int) (any, string, []by
 		return nil, "", nil, err
 
 	}
-	err = proofDb.Put([]byte(proofDbKey), proofDbValue, nil)
+	err = proofDb.Put([]byte(proofDbKey), proofDbValue)
 	if err != nil {
 		fmt.Println("Error saving proof:", err)
 		return nil, "", nil, err